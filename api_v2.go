@@ -0,0 +1,225 @@
+package go_signature_sdk
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// DefaultSignField 签名字段的默认名称
+const DefaultSignField = "sign"
+
+// Logger 日志接口，*log.Logger 满足该接口
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// Option Sign、Verify 的配置
+type Option func(*options)
+
+type options struct {
+	algorithm SignType
+	clock     func() time.Time
+	signField string
+	logger    Logger
+}
+
+// WithAlgorithm 指定签名算法
+// 包级函数默认使用MD5，SDK方法默认使用应用配置的算法；SDK验签时该算法需在应用允许的算法内
+func WithAlgorithm(signType SignType) Option {
+	return func(o *options) {
+		o.algorithm = signType
+	}
+}
+
+// WithClock 指定验签时判断时间戳和密钥有效期使用的时钟，默认使用 Config.Clock
+func WithClock(clock func() time.Time) Option {
+	return func(o *options) {
+		o.clock = clock
+	}
+}
+
+// WithSignField 指定签名字段的名称，默认 DefaultSignField
+// 签名时该字段不参与计算，验签时从该字段读取签名（VerifyParams.Sign 非空时优先）
+func WithSignField(field string) Option {
+	return func(o *options) {
+		o.signField = field
+	}
+}
+
+// WithLogger 指定验签失败时输出脱敏签名字符串的日志，默认不输出
+func WithLogger(logger Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{signField: DefaultSignField}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// Sign 使用 key 为 data 生成签名，不会修改 data
+// 非对称算法的 key 为PEM私钥
+func Sign(data map[string]interface{}, key string, opts ...Option) (string, error) {
+	o := newOptions(opts)
+	signType := o.algorithm
+	if signType == "" {
+		signType = SignTypeMD5
+	}
+	sign, _, err := GenerateSignWith(signType, withoutField(data, o.signField), key)
+	return sign, err
+}
+
+// Verify 使用 key 验证 data 中的签名，不会修改 data
+// 非对称算法的 key 为PEM公钥
+func Verify(data map[string]interface{}, key string, opts ...Option) error {
+	o := newOptions(opts)
+	signType := o.algorithm
+	if signType == "" {
+		signType = SignTypeMD5
+	}
+	sign, _ := data[o.signField].(string)
+	_, _, err := verifyAnySignType(withoutField(data, o.signField), sign, []SignType{signType}, func(SignType) []verifyKey {
+		return []verifyKey{{Key: key}}
+	}, o.logger)
+	return err
+}
+
+// Sign 使用应用的密钥生成签名，不会修改 params.Data
+// 算法依次取 WithAlgorithm、params.SignType 和应用配置的算法
+func (s *SignatureSDK) Sign(ctx context.Context, params *SignParams, opts ...Option) (string, error) {
+	sign, _, err := s.sign(ctx, params, newOptions(opts))
+	return sign, err
+}
+
+// Verify 验证IP白名单、应用状态、时间戳、签名和nonce，不会修改 params.Data
+// 验签失败时返回 *VerifyError 和非nil的结果，结果只有已完成阶段的字段有值（见 VerifyResult）；默认不输出日志
+func (s *SignatureSDK) Verify(ctx context.Context, params *VerifyParams, opts ...Option) (*VerifyResult, error) {
+	return s.verify(ctx, params, newOptions(opts))
+}
+
+// sign 生成签名，返回签名和脱敏后的签名字符串
+func (s *SignatureSDK) sign(ctx context.Context, params *SignParams, o *options) (string, string, error) {
+	appKey, err := s.GetAppKeyContext(ctx, params.AppID)
+	if err != nil {
+		return "", "", err
+	}
+	if err := statusError(appKey.Status); err != nil {
+		return "", "", err
+	}
+
+	data := withoutField(params.Data, o.signField)
+	applyTimestampNonce(data, params.Timestamp, params.Nonce)
+
+	signType := o.algorithm
+	if signType == "" {
+		signType = params.SignType
+	}
+	if signType == "" {
+		signType = appKey.SignType
+	}
+
+	// 非对称算法使用调用方提供的私钥
+	key := appKey.SecretKey
+	if IsAsymmetric(signType) {
+		if params.PrivateKey == "" {
+			return "", "", ErrPrivateKeyRequired
+		}
+		key = params.PrivateKey
+	}
+	return GenerateSignWith(signType, data, key)
+}
+
+// verify 验证请求并记录各阶段耗时，失败时返回 *VerifyError 和已完成阶段的结果
+func (s *SignatureSDK) verify(ctx context.Context, params *VerifyParams, o *options) (*VerifyResult, error) {
+	start := time.Now()
+	result := &VerifyResult{}
+	err := s.verifyRequest(ctx, params, o, result)
+	result.Timings.Total = time.Since(start)
+	if err != nil {
+		result.Reason = verifyReason(err)
+		return result, &VerifyError{Result: result, Err: err}
+	}
+	return result, nil
+}
+
+// verifyRequest 依次验证IP白名单、应用状态、时间戳、签名和nonce并填充 result
+// 在副本上写入时间戳和nonce并去掉签名字段
+func (s *SignatureSDK) verifyRequest(ctx context.Context, params *VerifyParams, o *options, result *VerifyResult) error {
+	lookupStart := time.Now()
+	appKey, err := s.VerifyIPsContext(ctx, params.AppID, params.ClientIP)
+	result.Timings.Lookup = time.Since(lookupStart)
+	if err != nil {
+		return err
+	}
+	result.AppKey = appKey
+
+	sign := params.Sign
+	if sign == "" {
+		sign, _ = params.Data[o.signField].(string)
+	}
+	request := *params
+	request.Data = withoutField(params.Data, o.signField)
+	applyTimestampNonce(request.Data, params.Timestamp, params.Nonce)
+
+	now := s.now()
+	if o.clock != nil {
+		now = o.clock()
+	}
+	if err := s.checkTimestamp(&request, now); err != nil {
+		return err
+	}
+
+	signTypes, err := appKey.acceptedSignTypes(request.Data)
+	if err != nil {
+		return err
+	}
+	if o.algorithm != "" {
+		if !slices.Contains(signTypes, o.algorithm) {
+			return fmt.Errorf("%w: %s", ErrSignTypeNotAllowed, o.algorithm)
+		}
+		signTypes = []SignType{o.algorithm}
+	}
+
+	result.CanonicalHash = canonicalHash(request.Data)
+	if sign == "" {
+		return ErrMissingSign
+	}
+	signStart := time.Now()
+	signType, keyID, err := verifyAnySignType(request.Data, sign, signTypes, func(t SignType) []verifyKey {
+		return appKey.verifyKeys(t, now)
+	}, o.logger)
+	result.Timings.Signature = time.Since(signStart)
+	if err != nil {
+		return err
+	}
+	result.SignType, result.KeyID = signType, keyID
+
+	replayStart := time.Now()
+	err = s.verifyReplay(ctx, &request)
+	result.Timings.Replay = time.Since(replayStart)
+	if err != nil {
+		return err
+	}
+	s.signTypeUsage.record(appKey.AppID, string(signType))
+	if keyID != "" {
+		s.secretUsage.record(appKey.AppID, keyID)
+	}
+	return nil
+}
+
+// withoutField 返回去掉 field 字段的浅拷贝
+func withoutField(data map[string]interface{}, field string) map[string]interface{} {
+	c := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		if k != field {
+			c[k] = v
+		}
+	}
+	return c
+}
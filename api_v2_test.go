@@ -0,0 +1,142 @@
+package go_signature_sdk
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"maps"
+	"testing"
+	"time"
+)
+
+// TestSignVerify 测试包级 Sign、Verify 及其选项
+func TestSignVerify(t *testing.T) {
+	data := map[string]interface{}{"app_id": "test_app", "amount": 100}
+
+	sign, err := Sign(data, "test_secret")
+	if err != nil {
+		t.Fatalf("签名生成失败: %v", err)
+	}
+	if _, ok := data["sign"]; ok {
+		t.Error("Sign 不应修改 data")
+	}
+	if old, _ := GenerateSign(data, "test_secret"); old != sign {
+		t.Errorf("默认算法应与 GenerateSign 一致: %s != %s", sign, old)
+	}
+
+	signed := maps.Clone(data)
+	signed["sign"] = sign
+	if err := Verify(signed, "test_secret"); err != nil {
+		t.Errorf("签名验证失败: %v", err)
+	}
+	if err := Verify(signed, "wrong_secret"); err != ErrInvalidSign {
+		t.Errorf("期望签名验证失败, 实际: %v", err)
+	}
+	if signed["sign"] != sign || len(signed) != len(data)+1 {
+		t.Error("Verify 不应修改 data")
+	}
+
+	// 自定义签名字段和算法
+	sign, _ = Sign(data, "test_secret", WithAlgorithm(SignTypeHMACSHA256), WithSignField("signature"))
+	signed = maps.Clone(data)
+	signed["signature"] = sign
+	if err := Verify(signed, "test_secret", WithAlgorithm(SignTypeHMACSHA256), WithSignField("signature")); err != nil {
+		t.Errorf("签名验证失败: %v", err)
+	}
+	if err := Verify(signed, "test_secret", WithSignField("signature")); err != ErrInvalidSign {
+		t.Errorf("算法不一致时期望签名验证失败, 实际: %v", err)
+	}
+
+	if _, err := Sign(data, "test_secret", WithAlgorithm("UNKNOWN")); !errors.Is(err, ErrUnsupportedSignType) {
+		t.Errorf("期望不支持的算法错误, 实际: %v", err)
+	}
+}
+
+// TestSDKSignVerify 测试 SDK 的 Sign、Verify 及其选项
+func TestSDKSignVerify(t *testing.T) {
+	sdk := createMemorySDK(t)
+	ctx := context.Background()
+	if err := sdk.CreateAppKey("test_app", "test_secret", nil, nil); err != nil {
+		t.Fatalf("创建测试应用失败: %v", err)
+	}
+
+	now := time.Now()
+	data := map[string]interface{}{"app_id": "test_app", "amount": 100}
+	sign, err := sdk.Sign(ctx, &SignParams{AppID: "test_app", Data: data, Timestamp: now.Unix(), Nonce: "n1"})
+	if err != nil {
+		t.Fatalf("签名生成失败: %v", err)
+	}
+	if len(data) != 2 {
+		t.Errorf("Sign 不应修改 Data: %v", data)
+	}
+
+	params := &VerifyParams{AppID: "test_app", ClientIP: "127.0.0.1", Timestamp: now.Unix(), Nonce: "n1", Sign: sign, Data: data}
+	result, err := sdk.Verify(ctx, params)
+	if err != nil {
+		t.Fatalf("签名验证失败: %v", err)
+	}
+	if result.AppKey.AppID != "test_app" || result.SignType != SignTypeMD5 {
+		t.Errorf("验签结果错误: %+v", result)
+	}
+	if len(data) != 2 {
+		t.Errorf("Verify 不应修改 Data: %v", data)
+	}
+
+	// WithClock 决定时间戳是否过期
+	later := func() time.Time { return now.Add(time.Hour) }
+	if _, err := sdk.Verify(ctx, params, WithClock(later)); !errors.Is(err, ErrExpiredRequest) {
+		t.Errorf("期望请求过期错误, 实际: %v", err)
+	}
+
+	// WithAlgorithm 需在应用允许的算法内
+	if _, err := sdk.Verify(ctx, params, WithAlgorithm(SignTypeHMACSHA256)); !errors.Is(err, ErrSignTypeNotAllowed) {
+		t.Errorf("期望算法不允许错误, 实际: %v", err)
+	}
+
+	// WithSignField 与 WithLogger
+	sign, _ = sdk.Sign(ctx, &SignParams{AppID: "test_app", Data: data}, WithSignField("signature"))
+	signed := maps.Clone(data)
+	signed["signature"] = sign
+	if _, err := sdk.Verify(ctx, &VerifyParams{AppID: "test_app", ClientIP: "127.0.0.1", Data: signed}, WithSignField("signature")); err != nil {
+		t.Errorf("签名验证失败: %v", err)
+	}
+
+	var buf bytes.Buffer
+	signed["signature"] = "wrong"
+	_, err = sdk.Verify(ctx, &VerifyParams{AppID: "test_app", ClientIP: "127.0.0.1", Data: signed}, WithSignField("signature"), WithLogger(log.New(&buf, "", 0)))
+	if !errors.Is(err, ErrInvalidSign) {
+		t.Errorf("期望签名验证失败, 实际: %v", err)
+	}
+	if buf.Len() == 0 {
+		t.Error("WithLogger 未输出验签失败日志")
+	}
+	if signed["signature"] != "wrong" {
+		t.Error("Verify 不应修改 Data")
+	}
+}
+
+// TestDeprecatedSignVerify 测试旧接口仍然可用
+func TestDeprecatedSignVerify(t *testing.T) {
+	sdk := createMemorySDK(t)
+	if err := sdk.CreateAppKey("test_app", "test_secret", nil, nil); err != nil {
+		t.Fatalf("创建测试应用失败: %v", err)
+	}
+
+	data := map[string]interface{}{"amount": 100}
+	err, _ := sdk.GenerateSign(&SignParams{AppID: "test_app", Data: data})
+	if err != nil {
+		t.Fatalf("签名生成失败: %v", err)
+	}
+	sign, _ := data["sign"].(string)
+	if sign == "" {
+		t.Fatal("GenerateSign 应写入 Data[\"sign\"]")
+	}
+
+	if err := sdk.VerifySign(&VerifyParams{AppID: "test_app", ClientIP: "127.0.0.1", Data: data}); err != nil {
+		t.Errorf("签名验证失败: %v", err)
+	}
+	if data["sign"] != sign {
+		t.Error("VerifySign 不应修改 Data")
+	}
+}
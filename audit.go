@@ -0,0 +1,178 @@
+package go_signature_sdk
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"time"
+)
+
+// AppKeyAction 应用变更的操作类型
+type AppKeyAction string
+
+const (
+	ActionCreate        AppKeyAction = "create"
+	ActionUpdate        AppKeyAction = "update" // UpdateAppKey
+	ActionPatch         AppKeyAction = "patch"  // PatchAppKey
+	ActionStatus        AppKeyAction = "status"
+	ActionDelete        AppKeyAction = "delete"
+	ActionRestore       AppKeyAction = "restore"
+	ActionPurge         AppKeyAction = "purge"
+	ActionWhitelist     AppKeyAction = "whitelist"
+	ActionAttributes    AppKeyAction = "attributes"
+	ActionSignTypes     AppKeyAction = "sign_types"
+	ActionPublicKey     AppKeyAction = "public_key"
+	ActionAddSecret     AppKeyAction = "add_secret"
+	ActionRemoveSecret  AppKeyAction = "remove_secret"
+	ActionPrimarySecret AppKeyAction = "primary_secret"
+	ActionRotateSecret  AppKeyAction = "rotate_secret"
+	ActionExpireSecrets AppKeyAction = "expire_secrets" // SweepSecrets 清理过期密钥
+)
+
+// redactedSecret 审计记录中代替密钥的值
+const redactedSecret = "[REDACTED]"
+
+// FieldChange 字段的旧值和新值，与 AppKey 的JSON格式一致
+type FieldChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// AppKeyEvent 一次应用变更的审计记录，记录只追加不修改
+type AppKeyEvent struct {
+	ID       int64                  `json:"id"`
+	AppID    string                 `json:"app_id"`
+	Action   AppKeyAction           `json:"action"`
+	Actor    string                 `json:"actor,omitempty"` // 见 WithActor
+	CreateAt int64                  `json:"create_at"`
+	Changes  map[string]FieldChange `json:"changes,omitempty"`  // 以JSON字段名为键，密钥只记录是否变化
+	Snapshot *AppKey                `json:"snapshot,omitempty"` // 变更后的应用，密钥已脱敏；清除时为空
+}
+
+type actorKey struct{}
+
+// WithActor 返回带有操作人的 context，应用的变更会以该操作人写入审计记录
+// 状态变更中的 StatusChange.Actor 优先
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// actorFromContext 返回 WithActor 设置的操作人
+func actorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+// withChangeActor 统一状态变更和审计记录的操作人：change.Actor 为空时使用 ctx 中的操作人，否则以它覆盖 ctx
+func withChangeActor(ctx context.Context, change *StatusChange) context.Context {
+	if change.Actor == "" {
+		change.Actor = actorFromContext(ctx)
+		return ctx
+	}
+	return WithActor(ctx, change.Actor)
+}
+
+// statusAction 状态变更的操作类型
+func statusAction(status AppStatus) AppKeyAction {
+	if status == StatusDeleted {
+		return ActionDelete
+	}
+	return ActionStatus
+}
+
+// newAppKeyEvent 根据变更前后的应用生成审计记录，before 为 nil 表示创建；没有字段变化时返回 nil
+func newAppKeyEvent(ctx context.Context, action AppKeyAction, before, after *AppKey, now int64) *AppKeyEvent {
+	changes := appKeyChanges(before, after)
+	if len(changes) == 0 {
+		return nil
+	}
+	return &AppKeyEvent{
+		AppID:    after.AppID,
+		Action:   action,
+		Actor:    actorFromContext(ctx),
+		CreateAt: now,
+		Changes:  changes,
+		Snapshot: redactAppKey(after),
+	}
+}
+
+// redactAppKey 返回密钥已脱敏的副本
+func redactAppKey(appKey *AppKey) *AppKey {
+	c := cloneAppKey(appKey)
+	if c.SecretKey != "" {
+		c.SecretKey = redactedSecret
+	}
+	for i := range c.Secrets {
+		if c.Secrets[i].Secret != "" {
+			c.Secrets[i].Secret = redactedSecret
+		}
+	}
+	return c
+}
+
+// appKeyChanges 比较变更前后的应用，忽略每次修改都会变化的 id、version 和 update_at
+func appKeyChanges(before, after *AppKey) map[string]FieldChange {
+	b, a := auditFields(before), auditFields(after)
+	changes := make(map[string]FieldChange)
+	for k, av := range a {
+		if bv, ok := b[k]; !ok || !reflect.DeepEqual(bv, av) {
+			changes[k] = FieldChange{Before: bv, After: av}
+		}
+	}
+	for k, bv := range b {
+		if _, ok := a[k]; !ok {
+			changes[k] = FieldChange{Before: bv}
+		}
+	}
+
+	// 脱敏后的密钥相同，按原值判断是否变化
+	if before != nil && after != nil {
+		if before.SecretKey != after.SecretKey {
+			changes["secret_key"] = FieldChange{Before: b["secret_key"], After: a["secret_key"]}
+		}
+		if _, ok := changes["secrets"]; !ok && !reflect.DeepEqual(before.Secrets, after.Secrets) {
+			changes["secrets"] = FieldChange{Before: b["secrets"], After: a["secrets"]}
+		}
+	}
+	return changes
+}
+
+// auditFields 将脱敏后的应用转为JSON字段
+func auditFields(appKey *AppKey) map[string]interface{} {
+	if appKey == nil {
+		return nil
+	}
+	data, _ := json.Marshal(redactAppKey(appKey))
+	var fields map[string]interface{}
+	json.Unmarshal(data, &fields)
+	delete(fields, "id")
+	delete(fields, "version")
+	delete(fields, "update_at")
+	return fields
+}
+
+// appKeyAt 返回 events 中 at 时刻（含）的应用快照，events 需按ID升序
+func appKeyAt(events []AppKeyEvent, at int64) (*AppKey, error) {
+	for i := len(events) - 1; i >= 0; i-- {
+		if events[i].CreateAt > at {
+			continue
+		}
+		if events[i].Snapshot == nil {
+			return nil, ErrAppNotFound
+		}
+		return cloneAppKey(events[i].Snapshot), nil
+	}
+	return nil, ErrAppNotFound
+}
+
+// History 返回应用的全部变更记录，按时间先后排序；应用被清除后仍可查询
+// 记录从升级到支持审计的版本后开始，密钥已脱敏
+func (s *SignatureSDK) History(ctx context.Context, appID string) ([]AppKeyEvent, error) {
+	return s.store.History(ctx, appID)
+}
+
+// GetAppKeyAt 返回应用在 t 时刻的状态，密钥已脱敏
+// 该时刻应用尚未创建、已被清除或没有审计记录时返回 ErrAppNotFound
+func (s *SignatureSDK) GetAppKeyAt(ctx context.Context, appID string, t time.Time) (*AppKey, error) {
+	return s.store.GetAppKeyAt(ctx, appID, t.Unix())
+}
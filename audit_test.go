@@ -0,0 +1,150 @@
+package go_signature_sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestAppKeyAt 测试按时间点查找快照
+func TestAppKeyAt(t *testing.T) {
+	events := []AppKeyEvent{
+		{ID: 1, CreateAt: 100, Snapshot: &AppKey{IPsWhite: []string{"10.0.0.1"}}},
+		{ID: 2, CreateAt: 200, Snapshot: &AppKey{IPsWhite: []string{"10.0.0.2"}}},
+		{ID: 3, CreateAt: 200, Snapshot: &AppKey{IPsWhite: []string{"10.0.0.3"}}},
+		{ID: 4, CreateAt: 300},
+	}
+	cases := []struct {
+		at int64
+		ip string
+	}{
+		{99, ""},
+		{100, "10.0.0.1"},
+		{199, "10.0.0.1"},
+		{200, "10.0.0.3"}, // 同一秒内以最后一条为准
+		{299, "10.0.0.3"},
+		{300, ""}, // 已清除
+	}
+	for _, tc := range cases {
+		appKey, err := appKeyAt(events, tc.at)
+		if tc.ip == "" {
+			if !errors.Is(err, ErrAppNotFound) {
+				t.Errorf("at=%d: 期望应用不存在错误, 实际: %v", tc.at, err)
+			}
+			continue
+		}
+		if err != nil || appKey.IPsWhite[0] != tc.ip {
+			t.Errorf("at=%d: 期望 %s, 实际: %v, %v", tc.at, tc.ip, appKey, err)
+		}
+	}
+}
+
+// TestHistory 测试内存存储的审计记录
+func TestHistory(t *testing.T) {
+	testHistory(t, createMemorySDK(t))
+}
+
+// TestSDKHistory 测试PostgreSQL存储的审计记录
+func TestSDKHistory(t *testing.T) {
+	sdk, db := createTestSDK(t)
+	defer teardownTestDB(t, db)
+	testHistory(t, sdk)
+}
+
+func testHistory(t *testing.T, sdk *SignatureSDK) {
+	ctx := WithActor(context.Background(), "alice")
+	appID := "test_app_history"
+	createAt := time.Now()
+	if err := sdk.CreateAppKey(appID, "first_secret", []string{"127.0.0.1"}, nil); err != nil {
+		t.Fatalf("创建测试应用失败: %v", err)
+	}
+	if err := sdk.AddWhitelistIPs(ctx, appID, "10.0.0.1"); err != nil {
+		t.Fatalf("追加白名单失败: %v", err)
+	}
+	// 没有变化的修改不产生记录
+	if err := sdk.AddWhitelistIPs(ctx, appID, "10.0.0.1"); err != nil {
+		t.Fatalf("追加白名单失败: %v", err)
+	}
+	if err := sdk.UpdateAppKey(appID, "second_secret", []string{"10.0.0.1"}, int(StatusActive), nil); err != nil {
+		t.Fatalf("更新应用失败: %v", err)
+	}
+	if _, err := sdk.RotateSecret(ctx, appID, time.Hour); err != nil {
+		t.Fatalf("轮换密钥失败: %v", err)
+	}
+	if err := sdk.SetStatus(ctx, appID, StatusChange{Status: StatusSuspended, Actor: "bob"}); err != nil {
+		t.Fatalf("修改状态失败: %v", err)
+	}
+
+	events, err := sdk.History(ctx, appID)
+	if err != nil {
+		t.Fatalf("查询审计记录失败: %v", err)
+	}
+	var actions []string
+	for _, event := range events {
+		actions = append(actions, string(event.Action)+":"+event.Actor)
+	}
+	expected := "create:,whitelist:alice,update:,rotate_secret:alice,status:bob"
+	if got := strings.Join(actions, ","); got != expected {
+		t.Fatalf("期望 %s, 实际 %s", expected, got)
+	}
+
+	create := events[0]
+	if create.Changes["app_id"].After != appID || create.Changes["app_id"].Before != nil {
+		t.Errorf("创建记录错误: %+v", create.Changes)
+	}
+	whitelist := events[1].Changes["ips_white"]
+	if len(events[1].Changes) != 1 || len(whitelist.After.([]interface{})) != 2 {
+		t.Errorf("白名单记录错误: %+v", events[1].Changes)
+	}
+	update := events[2].Changes
+	if update["secret_key"].Before != redactedSecret || update["secret_key"].After != redactedSecret {
+		t.Errorf("期望记录脱敏的密钥变化: %+v", update)
+	}
+	if _, ok := update["ips_white"]; !ok {
+		t.Errorf("期望记录白名单变化: %+v", update)
+	}
+	if events[4].Changes["status"].After != float64(StatusSuspended) || events[4].Snapshot.StatusActor != "bob" {
+		t.Errorf("状态记录错误: %+v", events[4])
+	}
+
+	data, _ := json.Marshal(events)
+	appKey, _ := sdk.GetAppKey(appID)
+	for _, secret := range []string{"first_secret", "second_secret", appKey.SecretKey} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("审计记录不应包含密钥 %s", secret)
+		}
+	}
+
+	// 时间点查询
+	if _, err := sdk.GetAppKeyAt(ctx, appID, createAt.Add(-time.Second)); !errors.Is(err, ErrAppNotFound) {
+		t.Errorf("期望应用不存在错误, 实际: %v", err)
+	}
+	snapshot, err := sdk.GetAppKeyAt(ctx, appID, time.Now())
+	if err != nil {
+		t.Fatalf("查询快照失败: %v", err)
+	}
+	if snapshot.Status != StatusSuspended || len(snapshot.IPsWhite) != 1 || snapshot.SecretKey != redactedSecret {
+		t.Errorf("快照错误: %+v", snapshot)
+	}
+
+	// 清除后仍可查询记录
+	if err := sdk.DeleteAppKey(ctx, appID, "", ""); err != nil {
+		t.Fatalf("删除应用失败: %v", err)
+	}
+	if _, err := sdk.PurgeDeleted(ctx, -time.Hour); err != nil {
+		t.Fatalf("清除应用失败: %v", err)
+	}
+	events, err = sdk.History(ctx, appID)
+	if err != nil || len(events) != 7 {
+		t.Fatalf("期望7条审计记录, 实际: %d, %v", len(events), err)
+	}
+	if events[5].Action != ActionDelete || events[5].Actor != "alice" || events[6].Action != ActionPurge {
+		t.Errorf("删除记录错误: %+v, %+v", events[5], events[6])
+	}
+	if _, err := sdk.GetAppKeyAt(ctx, appID, time.Now()); !errors.Is(err, ErrAppNotFound) {
+		t.Errorf("期望应用不存在错误, 实际: %v", err)
+	}
+}
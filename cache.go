@@ -0,0 +1,341 @@
+package go_signature_sdk
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 缓存默认配置
+const (
+	DefaultCacheMaxEntries  = 10000
+	DefaultCacheTTL         = time.Minute
+	DefaultCacheNegativeTTL = 5 * time.Second
+)
+
+// CacheConfig 应用密钥缓存配置
+type CacheConfig struct {
+	// MaxEntries 最大缓存条目数，超出后淘汰最久未使用的条目，默认 DefaultCacheMaxEntries
+	MaxEntries int
+	// TTL 缓存有效期，默认 DefaultCacheTTL
+	TTL time.Duration
+	// NegativeTTL 应用不存在结果的缓存有效期，默认 DefaultCacheNegativeTTL，小于0时不缓存
+	NegativeTTL time.Duration
+}
+
+// CacheStats 缓存统计
+type CacheStats struct {
+	Hits         int64 `json:"hits"`          // 命中有效应用
+	NegativeHits int64 `json:"negative_hits"` // 命中应用不存在
+	Misses       int64 `json:"misses"`        // 未命中，需要查询存储
+	Loads        int64 `json:"loads"`         // 实际查询存储的次数，并发的未命中会被合并
+	Evictions    int64 `json:"evictions"`     // 因容量淘汰的条目数
+	Entries      int   `json:"entries"`       // 当前条目数
+}
+
+// cacheEntry 缓存条目，appKey 为空表示应用不存在
+type cacheEntry struct {
+	appID    string
+	appKey   *AppKey
+	expireAt time.Time
+}
+
+// loadCall 进行中的存储查询
+type loadCall struct {
+	done   chan struct{}
+	appKey *AppKey
+	err    error
+}
+
+// cachedKeyStore 带LRU缓存的 KeyStore，写操作会自动使对应条目失效
+type cachedKeyStore struct {
+	KeyStore
+
+	config CacheConfig
+	clock  func() time.Time
+
+	mu         sync.Mutex
+	entries    map[string]*list.Element
+	lru        *list.List
+	loading    map[string]*loadCall
+	generation uint64 // 每次失效时递增，避免进行中的查询写入过期数据
+
+	hits, negativeHits, misses, loads, evictions atomic.Int64
+}
+
+func newCachedKeyStore(store KeyStore, config CacheConfig, clock func() time.Time) *cachedKeyStore {
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = DefaultCacheMaxEntries
+	}
+	if config.TTL <= 0 {
+		config.TTL = DefaultCacheTTL
+	}
+	if config.NegativeTTL == 0 {
+		config.NegativeTTL = DefaultCacheNegativeTTL
+	}
+	if clock == nil {
+		clock = time.Now
+	}
+	return &cachedKeyStore{
+		KeyStore: store,
+		config:   config,
+		clock:    clock,
+		entries:  make(map[string]*list.Element),
+		lru:      list.New(),
+		loading:  make(map[string]*loadCall),
+	}
+}
+
+// GetAppKey 优先从缓存获取应用，并发的未命中只查询一次存储
+func (c *cachedKeyStore) GetAppKey(ctx context.Context, appID string) (*AppKey, error) {
+	c.mu.Lock()
+	if elem, ok := c.entries[appID]; ok {
+		entry := elem.Value.(*cacheEntry)
+		if c.clock().Before(entry.expireAt) {
+			c.lru.MoveToFront(elem)
+			c.mu.Unlock()
+			if entry.appKey == nil {
+				c.negativeHits.Add(1)
+				return nil, ErrAppNotFound
+			}
+			c.hits.Add(1)
+			return cloneAppKey(entry.appKey), nil
+		}
+		c.removeElement(elem)
+	}
+	c.misses.Add(1)
+
+	if call, ok := c.loading[appID]; ok {
+		c.mu.Unlock()
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if call.err != nil {
+			// 发起查询的调用方被取消或超时，不应影响其他调用方
+			if isContextError(call.err) && ctx.Err() == nil {
+				return c.GetAppKey(ctx, appID)
+			}
+			return nil, call.err
+		}
+		return cloneAppKey(call.appKey), nil
+	}
+
+	call := &loadCall{done: make(chan struct{})}
+	c.loading[appID] = call
+	generation := c.generation
+	c.mu.Unlock()
+
+	c.loads.Add(1)
+	call.appKey, call.err = c.KeyStore.GetAppKey(ctx, appID)
+
+	c.mu.Lock()
+	delete(c.loading, appID)
+	if generation == c.generation {
+		switch {
+		case call.err == nil:
+			c.add(appID, call.appKey, c.config.TTL)
+		case errors.Is(call.err, ErrAppNotFound) && c.config.NegativeTTL > 0:
+			c.add(appID, nil, c.config.NegativeTTL)
+		}
+	}
+	c.mu.Unlock()
+	close(call.done)
+
+	if call.err != nil {
+		return nil, call.err
+	}
+	return cloneAppKey(call.appKey), nil
+}
+
+// isContextError 判断错误是否由 ctx 取消或超时引起
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// add 添加条目，调用方需持有锁
+func (c *cachedKeyStore) add(appID string, appKey *AppKey, ttl time.Duration) {
+	entry := &cacheEntry{appID: appID, appKey: appKey, expireAt: c.clock().Add(ttl)}
+	if elem, ok := c.entries[appID]; ok {
+		elem.Value = entry
+		c.lru.MoveToFront(elem)
+		return
+	}
+
+	c.entries[appID] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.config.MaxEntries {
+		c.removeElement(c.lru.Back())
+		c.evictions.Add(1)
+	}
+}
+
+// removeElement 删除条目，调用方需持有锁
+func (c *cachedKeyStore) removeElement(elem *list.Element) {
+	c.lru.Remove(elem)
+	delete(c.entries, elem.Value.(*cacheEntry).appID)
+}
+
+// Invalidate 使应用的缓存失效
+func (c *cachedKeyStore) Invalidate(appID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	if elem, ok := c.entries[appID]; ok {
+		c.removeElement(elem)
+	}
+}
+
+// Purge 清空缓存
+func (c *cachedKeyStore) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	c.entries = make(map[string]*list.Element)
+	c.lru.Init()
+}
+
+// Stats 返回缓存统计
+func (c *cachedKeyStore) Stats() CacheStats {
+	c.mu.Lock()
+	entries := c.lru.Len()
+	c.mu.Unlock()
+
+	return CacheStats{
+		Hits:         c.hits.Load(),
+		NegativeHits: c.negativeHits.Load(),
+		Misses:       c.misses.Load(),
+		Loads:        c.loads.Load(),
+		Evictions:    c.evictions.Load(),
+		Entries:      entries,
+	}
+}
+
+// CreateAppKey 创建应用，并清除该应用不存在的缓存
+func (c *cachedKeyStore) CreateAppKey(ctx context.Context, appKey *AppKey) error {
+	defer c.Invalidate(appKey.AppID)
+	return c.KeyStore.CreateAppKey(ctx, appKey)
+}
+
+// UpdateAppKey 更新应用并使缓存失效
+func (c *cachedKeyStore) UpdateAppKey(ctx context.Context, appKey *AppKey) error {
+	defer c.Invalidate(appKey.AppID)
+	return c.KeyStore.UpdateAppKey(ctx, appKey)
+}
+
+// PatchAppKey 修改应用并使缓存失效
+func (c *cachedKeyStore) PatchAppKey(ctx context.Context, appID string, patch *AppKeyPatch) (int64, error) {
+	defer c.Invalidate(appID)
+	return c.KeyStore.PatchAppKey(ctx, appID, patch)
+}
+
+// SetStatus 修改状态并使缓存失效
+func (c *cachedKeyStore) SetStatus(ctx context.Context, appID string, change *StatusChange) error {
+	defer c.Invalidate(appID)
+	return c.KeyStore.SetStatus(ctx, appID, change)
+}
+
+// RestoreAppKey 恢复应用并使缓存失效
+func (c *cachedKeyStore) RestoreAppKey(ctx context.Context, appID string, deletedAfter int64, change *StatusChange) error {
+	defer c.Invalidate(appID)
+	return c.KeyStore.RestoreAppKey(ctx, appID, deletedAfter, change)
+}
+
+// PurgeDeleted 清除应用并使相关应用的缓存失效
+func (c *cachedKeyStore) PurgeDeleted(ctx context.Context, deletedBefore, now int64) ([]string, error) {
+	purged, err := c.KeyStore.PurgeDeleted(ctx, deletedBefore, now)
+	for _, appID := range purged {
+		c.Invalidate(appID)
+	}
+	return purged, err
+}
+
+// AddWhitelistIPs 追加IP白名单并使缓存失效
+func (c *cachedKeyStore) AddWhitelistIPs(ctx context.Context, appID string, ips []string) error {
+	defer c.Invalidate(appID)
+	return c.KeyStore.AddWhitelistIPs(ctx, appID, ips)
+}
+
+// RemoveWhitelistIPs 删除IP白名单条目并使缓存失效
+func (c *cachedKeyStore) RemoveWhitelistIPs(ctx context.Context, appID string, ips []string) error {
+	defer c.Invalidate(appID)
+	return c.KeyStore.RemoveWhitelistIPs(ctx, appID, ips)
+}
+
+// MergeAttributes 合并Attributes并使缓存失效
+func (c *cachedKeyStore) MergeAttributes(ctx context.Context, appID string, attributes map[string]interface{}) error {
+	defer c.Invalidate(appID)
+	return c.KeyStore.MergeAttributes(ctx, appID, attributes)
+}
+
+// SetSignTypes 设置签名算法并使缓存失效
+func (c *cachedKeyStore) SetSignTypes(ctx context.Context, appID string, signType SignType, allowed []SignType) error {
+	defer c.Invalidate(appID)
+	return c.KeyStore.SetSignTypes(ctx, appID, signType, allowed)
+}
+
+// SetPublicKey 设置公钥并使缓存失效
+func (c *cachedKeyStore) SetPublicKey(ctx context.Context, appID, publicKey string) error {
+	defer c.Invalidate(appID)
+	return c.KeyStore.SetPublicKey(ctx, appID, publicKey)
+}
+
+// AddSecret 添加密钥并使缓存失效
+func (c *cachedKeyStore) AddSecret(ctx context.Context, appID string, secret *AppSecret) error {
+	defer c.Invalidate(appID)
+	return c.KeyStore.AddSecret(ctx, appID, secret)
+}
+
+// RemoveSecret 删除密钥并使缓存失效
+func (c *cachedKeyStore) RemoveSecret(ctx context.Context, appID, keyID string) error {
+	defer c.Invalidate(appID)
+	return c.KeyStore.RemoveSecret(ctx, appID, keyID)
+}
+
+// SetPrimarySecret 设置主密钥并使缓存失效
+func (c *cachedKeyStore) SetPrimarySecret(ctx context.Context, appID, keyID string) error {
+	defer c.Invalidate(appID)
+	return c.KeyStore.SetPrimarySecret(ctx, appID, keyID)
+}
+
+// RotateSecret 轮换密钥并使缓存失效
+func (c *cachedKeyStore) RotateSecret(ctx context.Context, appID string, secret *AppSecret, notAfter int64) (string, error) {
+	defer c.Invalidate(appID)
+	return c.KeyStore.RotateSecret(ctx, appID, secret, notAfter)
+}
+
+// DeleteExpiredSecrets 删除过期密钥并使相关应用的缓存失效
+func (c *cachedKeyStore) DeleteExpiredSecrets(ctx context.Context, now int64) ([]RetiredSecret, error) {
+	retired, err := c.KeyStore.DeleteExpiredSecrets(ctx, now)
+	for _, r := range retired {
+		c.Invalidate(r.AppID)
+	}
+	return retired, err
+}
+
+// InvalidateAppKey 使应用的缓存失效，未启用缓存时不做任何操作
+func (s *SignatureSDK) InvalidateAppKey(appID string) {
+	if s.cache != nil {
+		s.cache.Invalidate(appID)
+	}
+}
+
+// PurgeAppKeyCache 清空应用缓存，未启用缓存时不做任何操作
+func (s *SignatureSDK) PurgeAppKeyCache() {
+	if s.cache != nil {
+		s.cache.Purge()
+	}
+}
+
+// CacheStats 返回应用缓存统计，未启用缓存时返回零值
+func (s *SignatureSDK) CacheStats() CacheStats {
+	if s.cache == nil {
+		return CacheStats{}
+	}
+	return s.cache.Stats()
+}
@@ -0,0 +1,77 @@
+// verify-app-keys 校验 app_keys 中每个应用的 row_mac，报告被直接修改过的应用
+//
+//	verify-app-keys -dsn "host=localhost dbname=mydb sslmode=disable" -mac-key-file /etc/myapp/row_mac.key
+//
+// 存在校验失败的应用时退出码为1
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	_ "github.com/lib/pq"
+	"github.com/sulirlinc/go-signature-sdk"
+	"log"
+	"os"
+	"strings"
+)
+
+func main() {
+	dsn := flag.String("dsn", os.Getenv("DATABASE_URL"), "PostgreSQL连接串，默认读取 DATABASE_URL")
+	macKeyFile := flag.String("mac-key-file", "", "base64编码的row_mac密钥文件")
+	masterKeyFile := flag.String("master-key-file", "", "主密钥文件，密钥加密存储时需要")
+	sealMissing := flag.Bool("seal-missing", false, "为row_mac为空的应用补充row_mac，仅在确认数据未被篡改时使用")
+	flag.Parse()
+
+	if *dsn == "" || *macKeyFile == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	content, err := os.ReadFile(*macKeyFile)
+	if err != nil {
+		log.Fatal("读取row_mac密钥失败:", err)
+	}
+	macKey, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(content)))
+	if err != nil {
+		log.Fatal("row_mac密钥不是有效的base64:", err)
+	}
+
+	db, err := sql.Open("postgres", *dsn)
+	if err != nil {
+		log.Fatal("数据库连接失败:", err)
+	}
+	defer db.Close()
+
+	store := go_signature_sdk.NewPostgresKeyStore(db)
+	store.RowMACKey = macKey
+	if *masterKeyFile != "" {
+		if store.Encryption, err = go_signature_sdk.LoadLocalKeyProvider(*masterKeyFile); err != nil {
+			log.Fatal("加载主密钥失败:", err)
+		}
+	}
+
+	ctx := context.Background()
+	if *sealMissing {
+		n, err := store.SealRows(ctx)
+		if err != nil {
+			log.Fatal("补充row_mac失败:", err)
+		}
+		fmt.Printf("补充row_mac: %d\n", n)
+	}
+
+	tampered, err := store.VerifyRows(ctx)
+	if err != nil {
+		log.Fatal("校验失败:", err)
+	}
+	for _, row := range tampered {
+		fmt.Printf("%s\t%s\n", row.AppID, row.Reason)
+	}
+	if len(tampered) > 0 {
+		fmt.Printf("校验失败的应用: %d\n", len(tampered))
+		os.Exit(1)
+	}
+	fmt.Println("全部应用校验通过")
+}
@@ -0,0 +1,123 @@
+package go_signature_sdk
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// slowKeyStore 查询应用时阻塞到 ctx 结束，用于模拟慢数据库
+type slowKeyStore struct {
+	KeyStore
+	started chan struct{}
+}
+
+type slowKey struct{}
+
+func (s *slowKeyStore) GetAppKey(ctx context.Context, appID string) (*AppKey, error) {
+	if ctx.Value(slowKey{}) == nil {
+		return s.KeyStore.GetAppKey(ctx, appID)
+	}
+	s.started <- struct{}{}
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func newSlowSDK(t *testing.T, cache *CacheConfig) (*SignatureSDK, *slowKeyStore) {
+	store := &slowKeyStore{KeyStore: NewMemoryKeyStore(), started: make(chan struct{}, 1)}
+	sdk := NewSignatureSDK(&Config{KeyStore: store, Cache: cache})
+	if err := sdk.CreateAppKey("test_app", "test_secret", nil, nil); err != nil {
+		t.Fatalf("创建测试应用失败: %v", err)
+	}
+	return sdk, store
+}
+
+// TestVerifySignContext 测试验签在 ctx 超时后立即返回
+func TestVerifySignContext(t *testing.T) {
+	sdk, _ := newSlowSDK(t, nil)
+	ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), slowKey{}, true), 20*time.Millisecond)
+	defer cancel()
+
+	params := &VerifyParams{AppID: "test_app", ClientIP: "127.0.0.1", Data: map[string]interface{}{"sign": "x"}}
+	start := time.Now()
+	if err := sdk.VerifySignContext(ctx, params); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("期望超时错误, 实际: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("超时后未及时返回: %v", elapsed)
+	}
+
+	// 未阻塞的调用正常返回
+	if _, err := sdk.GetAppKeyContext(context.Background(), "test_app"); err != nil {
+		t.Errorf("查询应用失败: %v", err)
+	}
+}
+
+// TestCacheLoadCancelled 测试发起查询的调用方被取消时，等待同一查询的其他调用方重新查询
+func TestCacheLoadCancelled(t *testing.T) {
+	sdk, store := newSlowSDK(t, &CacheConfig{})
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), slowKey{}, true))
+
+	leader := make(chan error, 1)
+	go func() {
+		_, err := sdk.GetAppKeyContext(ctx, "test_app")
+		leader <- err
+	}()
+	<-store.started
+
+	follower := make(chan error, 1)
+	go func() {
+		_, err := sdk.GetAppKeyContext(context.Background(), "test_app")
+		follower <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	if err := <-leader; !errors.Is(err, context.Canceled) {
+		t.Errorf("期望取消错误, 实际: %v", err)
+	}
+	if err := <-follower; err != nil {
+		t.Errorf("其他调用方不应受影响: %v", err)
+	}
+}
+
+// TestMiddlewareRequestContext 测试中间件使用请求的 ctx 查询应用
+func TestMiddlewareRequestContext(t *testing.T) {
+	sdk, _ := newSlowSDK(t, nil)
+	var verifyErr error
+	handler := HTTPMiddleware(sdk, WithErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+		verifyErr = err
+		DefaultErrorHandler(w, r, err)
+	}))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("验签不应通过")
+	}))
+
+	ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), slowKey{}, true), 20*time.Millisecond)
+	defer cancel()
+	req := httptest.NewRequest(http.MethodGet, "/?sign=x", nil).WithContext(ctx)
+	req.Header.Set(HeaderAppID, "test_app")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if !errors.Is(verifyErr, context.DeadlineExceeded) {
+		t.Errorf("期望超时错误, 实际: %v", verifyErr)
+	}
+}
+
+// TestSDKQueryTimeout 测试 Config.QueryTimeout 限制数据库查询时间
+func TestSDKQueryTimeout(t *testing.T) {
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+	sdk, err := New(&Config{DB: db, QueryTimeout: time.Nanosecond})
+	if err != nil {
+		t.Fatalf("创建SDK失败: %v", err)
+	}
+	if _, err := sdk.GetAppKey("test_app"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("期望超时错误, 实际: %v", err)
+	}
+	if err := sdk.CreateAppKey("test_app", "test_secret", nil, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("期望超时错误, 实际: %v", err)
+	}
+}
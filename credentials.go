@@ -0,0 +1,227 @@
+package go_signature_sdk
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"hash/crc32"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// CredentialEnvironment 凭证环境，作为前缀的一部分便于区分和扫描
+type CredentialEnvironment string
+
+const (
+	CredentialLive CredentialEnvironment = "live"
+	CredentialTest CredentialEnvironment = "test"
+)
+
+// 凭证格式：<类型>_<环境>_<随机base62><6位base62 CRC32校验码>
+// 例如 app_live_4qY7...Ab12Cd、sk_test_Z9x...09aBcD
+const (
+	appIDPrefix  = "app"
+	secretPrefix = "sk"
+
+	appIDRandomLen  = 16 // 约95位熵，app_id 总长31，不超过 VARCHAR(32)
+	secretRandomLen = 40 // 约238位熵
+	checksumLen     = 6
+)
+
+const base62Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// IssueOptions 签发凭证的选项
+type IssueOptions struct {
+	Environment CredentialEnvironment // 默认 CredentialLive
+	IPsWhite    []string
+	Attributes  map[string]interface{}
+	SignType    SignType // 默认 MD5
+	Pending     bool     // 为true时应用创建为 StatusPending，审核通过后再通过 SetStatus 启用
+}
+
+// Credentials 新签发的凭证
+// Secret 只在签发时返回一次，调用方需立即交付合作方；String 输出时会隐藏 Secret
+type Credentials struct {
+	AppID  string `json:"app_id"`
+	Secret string `json:"secret"`
+	KeyID  string `json:"key_id"`
+}
+
+// String 隐藏密钥，避免凭证被意外写入日志
+func (c Credentials) String() string {
+	return fmt.Sprintf("{AppID:%s Secret:%s KeyID:%s}", c.AppID, redactCredential(c.Secret), c.KeyID)
+}
+
+// IssueCredentials 生成随机的应用ID和密钥并创建应用
+func (s *SignatureSDK) IssueCredentials(ctx context.Context, opts IssueOptions) (*Credentials, error) {
+	env := opts.Environment
+	if env == "" {
+		env = CredentialLive
+	}
+	if env != CredentialLive && env != CredentialTest {
+		return nil, fmt.Errorf("%w: 未知的凭证环境 %s", ErrInvalidCredential, env)
+	}
+	signType := opts.SignType
+	if signType == "" {
+		signType = SignTypeMD5
+	}
+	if _, err := GetSigner(signType); err != nil {
+		return nil, err
+	}
+
+	appID, err := generateCredential(appIDPrefix, env, appIDRandomLen)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := generateCredential(secretPrefix, env, secretRandomLen)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.policy.ValidateAppID(appID); err != nil {
+		return nil, err
+	}
+	if err := s.policy.ValidateSecret(secret); err != nil {
+		return nil, err
+	}
+
+	ipsWhite := opts.IPsWhite
+	if ipsWhite == nil {
+		ipsWhite = []string{}
+	}
+	attributes := opts.Attributes
+	if attributes == nil {
+		attributes = map[string]interface{}{}
+	}
+
+	status := StatusActive
+	if opts.Pending {
+		status = StatusPending
+	}
+
+	appKey := &AppKey{
+		AppID:      appID,
+		SecretKey:  secret,
+		IPsWhite:   ipsWhite,
+		Status:     status,
+		CreateAt:   time.Now().Unix(),
+		Attributes: attributes,
+		SignType:   signType,
+	}
+	if err := s.store.CreateAppKey(ctx, appKey); err != nil {
+		return nil, err
+	}
+
+	creds := &Credentials{AppID: appID, Secret: secret}
+	if primary := appKey.primarySecret(); primary != nil {
+		creds.KeyID = primary.KeyID
+	}
+	return creds, nil
+}
+
+// ValidateCredential 校验SDK签发的应用ID或密钥的格式和校验码，可用于泄露扫描
+func ValidateCredential(credential string) error {
+	if _, _, err := parseCredential(credential); err != nil {
+		return err
+	}
+	return nil
+}
+
+// checkAppIDFormat 在查询存储前拒绝校验码错误的应用ID
+// 只检查与SDK签发格式一致（app_live_ / app_test_ 前缀加定长base62）的应用ID，
+// 其他自定义应用ID（如 app_1、早期手工创建的 app_live_merchant）照常查询存储
+func checkAppIDFormat(appID string) error {
+	for _, env := range []CredentialEnvironment{CredentialLive, CredentialTest} {
+		rest, ok := strings.CutPrefix(appID, appIDPrefix+"_"+string(env)+"_")
+		if ok && len(rest) == appIDRandomLen+checksumLen && strings.Trim(rest, base62Alphabet) == "" {
+			_, _, err := parseCredential(appID)
+			return err
+		}
+	}
+	return nil
+}
+
+// generateCredential 生成带前缀和校验码的随机凭证
+func generateCredential(kind string, env CredentialEnvironment, randomLen int) (string, error) {
+	random, err := randomBase62(randomLen)
+	if err != nil {
+		return "", err
+	}
+	body := kind + "_" + string(env) + "_" + random
+	return body + credentialChecksum(body), nil
+}
+
+// parseCredential 解析凭证，返回类型和环境
+func parseCredential(credential string) (string, CredentialEnvironment, error) {
+	parts := strings.SplitN(credential, "_", 3)
+	if len(parts) != 3 {
+		return "", "", fmt.Errorf("%w: 格式错误", ErrInvalidCredential)
+	}
+
+	kind, env, rest := parts[0], CredentialEnvironment(parts[1]), parts[2]
+	var randomLen int
+	switch kind {
+	case appIDPrefix:
+		randomLen = appIDRandomLen
+	case secretPrefix:
+		randomLen = secretRandomLen
+	default:
+		return "", "", fmt.Errorf("%w: 未知的前缀 %s", ErrInvalidCredential, kind)
+	}
+	if env != CredentialLive && env != CredentialTest {
+		return "", "", fmt.Errorf("%w: 未知的环境 %s", ErrInvalidCredential, env)
+	}
+	if len(rest) != randomLen+checksumLen || strings.Trim(rest, base62Alphabet) != "" {
+		return "", "", fmt.Errorf("%w: 格式错误", ErrInvalidCredential)
+	}
+
+	body := credential[:len(credential)-checksumLen]
+	if credential[len(body):] != credentialChecksum(body) {
+		return "", "", fmt.Errorf("%w: 校验码错误", ErrInvalidCredential)
+	}
+	return kind, env, nil
+}
+
+// credentialEnvironment 返回SDK签发的应用ID所属的环境，其他应用ID返回 CredentialLive
+func credentialEnvironment(appID string) CredentialEnvironment {
+	if kind, env, err := parseCredential(appID); err == nil && kind == appIDPrefix {
+		return env
+	}
+	return CredentialLive
+}
+
+// credentialChecksum 返回 body 的CRC32，编码为定长base62
+func credentialChecksum(body string) string {
+	sum := crc32.ChecksumIEEE([]byte(body))
+	b := make([]byte, checksumLen)
+	for i := checksumLen - 1; i >= 0; i-- {
+		b[i] = base62Alphabet[sum%62]
+		sum /= 62
+	}
+	return string(b)
+}
+
+// randomBase62 使用 crypto/rand 生成均匀分布的base62字符串
+func randomBase62(n int) (string, error) {
+	b := make([]byte, n)
+	max := big.NewInt(int64(len(base62Alphabet)))
+	for i := range b {
+		idx, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("生成随机凭证失败: %w", err)
+		}
+		b[i] = base62Alphabet[idx.Int64()]
+	}
+	return string(b), nil
+}
+
+// redactCredential 保留凭证前缀，隐藏随机部分
+func redactCredential(credential string) string {
+	if credential == "" {
+		return ""
+	}
+	if i := strings.LastIndex(credential, "_"); i >= 0 {
+		return credential[:i+1] + "***"
+	}
+	return "***"
+}
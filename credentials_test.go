@@ -0,0 +1,124 @@
+package go_signature_sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// TestIssueCredentials 测试签发凭证
+func TestIssueCredentials(t *testing.T) {
+	store := &countingKeyStore{KeyStore: NewMemoryKeyStore()}
+	sdk := NewSignatureSDK(&Config{KeyStore: store})
+	ctx := context.Background()
+
+	creds, err := sdk.IssueCredentials(ctx, IssueOptions{IPsWhite: []string{"127.0.0.1"}})
+	if err != nil {
+		t.Fatalf("签发凭证失败: %v", err)
+	}
+	if !strings.HasPrefix(creds.AppID, "app_live_") || len(creds.AppID) > 32 {
+		t.Errorf("应用ID格式错误: %s", creds.AppID)
+	}
+	if !strings.HasPrefix(creds.Secret, "sk_live_") || creds.KeyID == "" {
+		t.Errorf("密钥格式错误: %+v", creds)
+	}
+	for _, c := range []string{creds.AppID, creds.Secret} {
+		if err := ValidateCredential(c); err != nil {
+			t.Errorf("凭证校验失败: %s, %v", c, err)
+		}
+	}
+	if s := fmt.Sprint(creds); strings.Contains(s, creds.Secret) || !strings.Contains(s, "sk_live_***") {
+		t.Errorf("String 未隐藏密钥: %s", s)
+	}
+
+	// 使用签发的凭证签名和验签
+	params := &SignParams{AppID: creds.AppID, Data: map[string]interface{}{"user_id": "12345"}}
+	if err, _ := sdk.GenerateSign(params); err != nil {
+		t.Fatalf("签名生成失败: %v", err)
+	}
+	expected, _ := GenerateSign(map[string]interface{}{"user_id": "12345"}, creds.Secret)
+	if params.Data["sign"] != expected {
+		t.Errorf("未使用签发的密钥签名")
+	}
+	if err := sdk.VerifySign(&VerifyParams{AppID: creds.AppID, Data: copyData(params.Data), ClientIP: "127.0.0.1"}); err != nil {
+		t.Errorf("签名验证失败: %v", err)
+	}
+
+	// 校验码错误的应用ID不查询存储
+	forged := flipChar(creds.AppID, len(creds.AppID)-1)
+	gets := store.gets.Load()
+	err = sdk.VerifySign(&VerifyParams{AppID: forged, Data: copyData(params.Data), ClientIP: "127.0.0.1"})
+	if !errors.Is(err, ErrInvalidCredential) {
+		t.Errorf("期望凭证校验码错误, 实际: %v", err)
+	}
+	if store.gets.Load() != gets {
+		t.Errorf("校验码错误的应用ID不应查询存储")
+	}
+
+	// 非SDK签发的应用ID照常查询
+	for _, appID := range []string{"legacy_app", "app_1", "app_live_merchant"} {
+		if err := sdk.VerifySign(&VerifyParams{AppID: appID, Data: map[string]interface{}{"sign": "x"}}); err != ErrAppNotFound {
+			t.Errorf("期望应用不存在错误: %s, 实际: %v", appID, err)
+		}
+	}
+
+	// 早期手工创建、带 app_live_ 前缀但没有校验码的应用仍可验签
+	if err := sdk.CreateAppKey("app_live_merchant_42", "legacy_secret", nil, nil); err != nil {
+		t.Fatalf("创建测试应用失败: %v", err)
+	}
+	legacy := map[string]interface{}{"user_id": "12345"}
+	legacy["sign"], _ = GenerateSign(legacy, "legacy_secret")
+	if err := sdk.VerifySign(&VerifyParams{AppID: "app_live_merchant_42", Data: legacy, ClientIP: "127.0.0.1"}); err != nil {
+		t.Errorf("早期应用验签失败: %v", err)
+	}
+
+	test, err := sdk.IssueCredentials(ctx, IssueOptions{Environment: CredentialTest})
+	if err != nil {
+		t.Fatalf("签发测试凭证失败: %v", err)
+	}
+	if !strings.HasPrefix(test.AppID, "app_test_") || !strings.HasPrefix(test.Secret, "sk_test_") {
+		t.Errorf("测试凭证前缀错误: %+v", test)
+	}
+	rotated, err := sdk.RotateSecret(ctx, test.AppID, 0)
+	if err != nil || !strings.HasPrefix(rotated.Secret, "sk_test_") {
+		t.Errorf("轮换后的密钥应保持环境前缀: %v, %v", rotated, err)
+	}
+
+	if _, err := sdk.IssueCredentials(ctx, IssueOptions{Environment: "prod"}); !errors.Is(err, ErrInvalidCredential) {
+		t.Errorf("期望未知环境错误, 实际: %v", err)
+	}
+}
+
+// TestValidateCredential 测试凭证格式校验
+func TestValidateCredential(t *testing.T) {
+	valid, err := generateCredential(secretPrefix, CredentialLive, secretRandomLen)
+	if err != nil {
+		t.Fatalf("生成凭证失败: %v", err)
+	}
+
+	invalid := []string{
+		"",
+		"my_secret_key",
+		"sk_live_short",
+		"sk_prod_" + valid[len("sk_live_"):],
+		"ak_live_" + valid[len("sk_live_"):],
+		valid[:len(valid)-1],
+		flipChar(valid, 10),
+	}
+	for _, c := range invalid {
+		if err := ValidateCredential(c); !errors.Is(err, ErrInvalidCredential) {
+			t.Errorf("期望凭证无效: %q, %v", c, err)
+		}
+	}
+}
+
+// flipChar 替换第i个字符，模拟凭证中的输入错误
+func flipChar(s string, i int) string {
+	c := byte('A')
+	if s[i] == c {
+		c = 'B'
+	}
+	return s[:i] + string(c) + s[i+1:]
+}
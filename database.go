@@ -1,26 +1,51 @@
 package go_signature_sdk
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
 	"github.com/lib/pq"
+	"sort"
+	"strings"
 	"time"
 )
 
-// GetAppKey 根据app_id获取应用密钥信息
-func (s *SignatureSDK) GetAppKey(appID string) (*AppKey, error) {
-	query := `
-		SELECT id, app_id, secret_key, ips_white, status, create_at, update_at, attributes
-		FROM app_keys 
-		WHERE app_id = $1
-	`
+// PostgresKeyStore 基于PostgreSQL的应用密钥存储
+type PostgresKeyStore struct {
+	// Encryption 密钥加密提供者，为空时密钥明文存储；已加密的记录仍需要它解密
+	Encryption KeyEncryptionProvider
+	// RowMACKey 计算和校验 row_mac 的密钥，为空时不校验；启用后所有实例都需要配置
+	RowMACKey []byte
+	// QueryTimeout 每次查询或事务的超时时间，ctx 的截止时间更早时以 ctx 为准；为0时只受 ctx 控制
+	QueryTimeout time.Duration
+
+	db *sql.DB
+}
+
+// NewPostgresKeyStore 创建PostgreSQL应用密钥存储
+// 表结构由 Migrate 创建
+func NewPostgresKeyStore(db *sql.DB) *PostgresKeyStore {
+	return &PostgresKeyStore{db: db}
+}
 
-	row := s.db.QueryRow(query, appID)
+// appKeyColumns app_keys 查询列，顺序与 scanAppKey 一致
+const appKeyColumns = `id, app_id, secret_key, ips_white, status, create_at, update_at, attributes,
+		       sign_type, allowed_sign_types, public_key, key_version, row_mac, version,
+		       status_reason, status_actor, status_changed_at, deleted_at`
 
+// rowScanner 兼容 *sql.Row 和 *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanAppKey 扫描一行应用密钥，同时返回 row_mac；decrypt 为false时不解密主密钥，SecretKey 为空
+func (p *PostgresKeyStore) scanAppKey(ctx context.Context, row rowScanner, decrypt bool) (*AppKey, string, error) {
 	var appKey AppKey
+	var keyVersion, rowMAC string
 	var ipsWhiteJSON []byte
 	var attributesJSON []byte
+	var allowedSignTypesJSON []byte
 	var updateAt sql.NullInt64
 
 	err := row.Scan(
@@ -32,73 +57,1143 @@ func (s *SignatureSDK) GetAppKey(appID string) (*AppKey, error) {
 		&appKey.CreateAt,
 		&updateAt,
 		&attributesJSON,
+		&appKey.SignType,
+		&allowedSignTypesJSON,
+		&appKey.PublicKey,
+		&keyVersion,
+		&rowMAC,
+		&appKey.Version,
+		&appKey.StatusReason,
+		&appKey.StatusActor,
+		&appKey.StatusChangedAt,
+		&appKey.DeletedAt,
 	)
-
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, ErrAppNotFound
-		}
-		return nil, fmt.Errorf("查询应用密钥失败: %w", err)
+		return nil, "", err
+	}
+
+	if !decrypt {
+		appKey.SecretKey = ""
+	} else if appKey.SecretKey, err = p.openSecret(ctx, appKey.SecretKey, keyVersion, appKeyAAD(appKey.AppID)); err != nil {
+		return nil, "", err
 	}
 
 	// 解析IP白名单JSON
 	if err := json.Unmarshal(ipsWhiteJSON, &appKey.IPsWhite); err != nil {
-		return nil, fmt.Errorf("解析IP白名单失败: %w", err)
+		return nil, "", fmt.Errorf("解析IP白名单失败: %w", err)
 	}
 
 	// 解析Attributes
 	if err := json.Unmarshal(attributesJSON, &appKey.Attributes); err != nil {
-		return nil, fmt.Errorf("解析Attributes失败: %w", err)
+		return nil, "", fmt.Errorf("解析Attributes失败: %w", err)
+	}
+
+	// 解析允许的签名算法
+	if err := json.Unmarshal(allowedSignTypesJSON, &appKey.AllowedSignTypes); err != nil {
+		return nil, "", fmt.Errorf("解析签名算法失败: %w", err)
 	}
 
 	if updateAt.Valid {
 		appKey.UpdateAt = &updateAt.Int64
 	}
 
-	return &appKey, nil
+	return &appKey, rowMAC, nil
+}
+
+// appSecretsColumn 应用全部密钥的JSON数组，主密钥在前，字段与 secretRow 一致
+const appSecretsColumn = `(
+		SELECT COALESCE(json_agg(json_build_object(
+			'key_id', s.key_id, 'secret', s.secret, 'is_primary', s.is_primary, 'not_before', s.not_before,
+			'not_after', s.not_after, 'create_at', s.create_at, 'key_version', s.key_version)
+			ORDER BY s.is_primary DESC, s.create_at DESC, s.id DESC), '[]')
+		FROM app_secrets s WHERE s.app_id = app_keys.app_id)`
+
+// secretRow appSecretsColumn 中的一个密钥，key_version 非空时 secret 为密文
+type secretRow struct {
+	KeyID      string `json:"key_id"`
+	Secret     string `json:"secret"`
+	Primary    bool   `json:"is_primary"`
+	NotBefore  int64  `json:"not_before"`
+	NotAfter   int64  `json:"not_after"`
+	CreateAt   int64  `json:"create_at"`
+	KeyVersion string `json:"key_version"`
+}
+
+// secretsScanner 在 appKeyColumns 之后多扫描一列 appSecretsColumn
+type secretsScanner struct {
+	rowScanner
+	secretsJSON *[]byte
+}
+
+func (s secretsScanner) Scan(dest ...interface{}) error {
+	return s.rowScanner.Scan(append(dest, s.secretsJSON)...)
+}
+
+// readAppKey 读取应用及其全部密钥，forUpdate 为true时锁定应用行
+// 不加锁时在一条语句中读取应用行和密钥，二者来自同一快照，不需要事务；
+// 加锁时先锁定应用行再查询密钥，修改密钥都需要先锁定应用行，因此两次查询的结果一致
+func (p *PostgresKeyStore) readAppKey(ctx context.Context, q queryer, appID string, forUpdate bool) (*AppKey, string, error) {
+	if forUpdate {
+		query := `SELECT ` + appKeyColumns + ` FROM app_keys WHERE app_id = $1 FOR UPDATE`
+		appKey, rowMAC, err := p.scanAppKey(ctx, q.QueryRowContext(ctx, query, appID), true)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil, "", ErrAppNotFound
+			}
+			return nil, "", fmt.Errorf("查询应用密钥失败: %w", err)
+		}
+		if appKey.Secrets, err = p.loadSecrets(ctx, q, appID); err != nil {
+			return nil, "", err
+		}
+		return appKey, rowMAC, nil
+	}
+
+	query := `SELECT ` + appKeyColumns + `, ` + appSecretsColumn + ` FROM app_keys WHERE app_id = $1`
+	var secretsJSON []byte
+	appKey, rowMAC, err := p.scanAppKey(ctx, secretsScanner{q.QueryRowContext(ctx, query, appID), &secretsJSON}, true)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, "", ErrAppNotFound
+		}
+		return nil, "", fmt.Errorf("查询应用密钥失败: %w", err)
+	}
+
+	var rows []secretRow
+	if err := json.Unmarshal(secretsJSON, &rows); err != nil {
+		return nil, "", fmt.Errorf("解析应用密钥失败: %w", err)
+	}
+	for _, row := range rows {
+		secret, err := p.openSecret(ctx, row.Secret, row.KeyVersion, appSecretAAD(appID, row.KeyID))
+		if err != nil {
+			return nil, "", err
+		}
+		appKey.Secrets = append(appKey.Secrets, AppSecret{
+			KeyID:     row.KeyID,
+			Secret:    secret,
+			Primary:   row.Primary,
+			NotBefore: row.NotBefore,
+			NotAfter:  row.NotAfter,
+			CreateAt:  row.CreateAt,
+		})
+	}
+	return appKey, rowMAC, nil
+}
+
+// GetAppKey 根据app_id获取应用密钥信息，配置了 RowMACKey 时校验 row_mac
+func (p *PostgresKeyStore) GetAppKey(ctx context.Context, appID string) (*AppKey, error) {
+	ctx, cancel := withQueryTimeout(ctx, p.QueryTimeout)
+	defer cancel()
+
+	appKey, rowMAC, err := p.readAppKey(ctx, p.db, appID, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkRowMAC(p.RowMACKey, appKey, rowMAC); err != nil {
+		return nil, err
+	}
+	return appKey, nil
+}
+
+// listSortColumns ListAppKeys 排序字段对应的SQL表达式
+var listSortColumns = map[AppKeySort]string{
+	SortByID:       "id",
+	SortByAppID:    "app_id",
+	SortByCreateAt: "create_at",
+	SortByUpdateAt: "COALESCE(update_at, 0)",
+}
+
+// ListAppKeys 按条件分页查询应用
+// 未返回密钥时不解密也不读取 app_secrets；返回密钥时一次查询本页全部密钥，row_mac 校验失败的应用记录在 Tampered 中
+func (p *PostgresKeyStore) ListAppKeys(ctx context.Context, filter *AppKeyFilter) (*AppKeyPage, error) {
+	f := *filter
+	cursor, err := normalizeFilter(&f)
+	if err != nil {
+		return nil, err
+	}
+
+	var where []string
+	var args []interface{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if len(f.Status) > 0 {
+		statuses := make([]int64, len(f.Status))
+		for i, st := range f.Status {
+			statuses[i] = int64(st)
+		}
+		where = append(where, "status = ANY("+arg(pq.Array(statuses))+")")
+	} else if !f.IncludeDeleted {
+		where = append(where, "status <> "+arg(int64(StatusDeleted)))
+	}
+	if f.CreatedAfter != 0 {
+		where = append(where, "create_at >= "+arg(f.CreatedAfter))
+	}
+	if f.CreatedBefore != 0 {
+		where = append(where, "create_at < "+arg(f.CreatedBefore))
+	}
+	if f.UpdatedAfter != 0 {
+		where = append(where, "update_at >= "+arg(f.UpdatedAfter))
+	}
+	if f.UpdatedBefore != 0 {
+		where = append(where, "update_at < "+arg(f.UpdatedBefore))
+	}
+	if f.WhitelistIP != "" {
+		where = append(where, "app_keys_whitelist_contains(ips_white, "+arg(f.WhitelistIP)+")")
+	}
+	if len(f.Attributes) > 0 {
+		attributesJSON, err := json.Marshal(f.Attributes)
+		if err != nil {
+			return nil, fmt.Errorf("序列化Attributes失败: %w", err)
+		}
+		where = append(where, "attributes @> "+arg(string(attributesJSON))+"::jsonb")
+	}
+
+	sortColumn := listSortColumns[f.SortBy]
+	op, direction := ">", "ASC"
+	if f.Descending {
+		op, direction = "<", "DESC"
+	}
+	if cursor != nil {
+		switch f.SortBy {
+		case SortByID:
+			where = append(where, "id "+op+" "+arg(cursor.ID))
+		case SortByAppID:
+			where = append(where, fmt.Sprintf("(%s, id) %s (%s, %s)", sortColumn, op, arg(cursor.Str), arg(cursor.ID)))
+		default:
+			where = append(where, fmt.Sprintf("(%s, id) %s (%s, %s)", sortColumn, op, arg(cursor.Int), arg(cursor.ID)))
+		}
+	}
+
+	query := `SELECT ` + appKeyColumns + ` FROM app_keys`
+	if len(where) > 0 {
+		query += ` WHERE ` + strings.Join(where, " AND ")
+	}
+	query += fmt.Sprintf(` ORDER BY %s %s, id %s LIMIT %s`, sortColumn, direction, direction, arg(f.Limit+1))
+
+	var appKeys []*AppKey
+	var rowMACs []string
+	var hasMore bool
+	err = p.withSnapshot(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("查询应用失败: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			appKey, rowMAC, err := p.scanAppKey(ctx, rows, f.IncludeSecrets)
+			if err != nil {
+				return fmt.Errorf("查询应用失败: %w", err)
+			}
+			appKeys = append(appKeys, appKey)
+			rowMACs = append(rowMACs, rowMAC)
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("查询应用失败: %w", err)
+		}
+		rows.Close()
+
+		if len(appKeys) > f.Limit {
+			appKeys, hasMore = appKeys[:f.Limit], true
+		}
+		if !f.IncludeSecrets || len(appKeys) == 0 {
+			return nil
+		}
+		appIDs := make([]string, len(appKeys))
+		for i, appKey := range appKeys {
+			appIDs[i] = appKey.AppID
+		}
+		secrets, err := p.loadSecretsFor(ctx, tx, appIDs)
+		if err != nil {
+			return err
+		}
+		for _, appKey := range appKeys {
+			appKey.Secrets = secrets[appKey.AppID]
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	page := &AppKeyPage{AppKeys: []*AppKey{}}
+	if hasMore {
+		page.NextCursor = encodeListCursor(&f, appKeys[len(appKeys)-1])
+	}
+	for i, appKey := range appKeys {
+		if f.IncludeSecrets {
+			if err := checkRowMAC(p.RowMACKey, appKey, rowMACs[i]); err != nil {
+				page.Tampered = append(page.Tampered, TamperedRow{AppID: appKey.AppID, Reason: err.Error()})
+				continue
+			}
+		}
+		page.AppKeys = append(page.AppKeys, appKey)
+	}
+	return page, nil
+}
+
+// CreateAppKey 创建应用密钥，同时写入应用的全部密钥
+func (p *PostgresKeyStore) CreateAppKey(ctx context.Context, appKey *AppKey) error {
+	ipsWhiteJSON, err := json.Marshal(appKey.IPsWhite)
+	if err != nil {
+		return fmt.Errorf("序列化IP白名单失败: %w", err)
+	}
+	if err := normalizeSecrets(appKey); err != nil {
+		return err
+	}
+
+	secretKey, keyVersion, err := p.sealSecret(ctx, appKey.SecretKey, appKeyAAD(appKey.AppID))
+	if err != nil {
+		return err
+	}
+
+	return p.withTx(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		query := `
+		INSERT INTO app_keys (app_id, secret_key, ips_white, status, create_at, attributes, sign_type, key_version)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, version
+	`
+		d, _ := json.Marshal(appKey.Attributes)
+		err := tx.QueryRowContext(ctx, query, appKey.AppID, secretKey, ipsWhiteJSON, appKey.Status,
+			appKey.CreateAt, d, appKey.SignType, keyVersion).Scan(&appKey.ID, &appKey.Version)
+		if err != nil {
+			if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+				return fmt.Errorf("%w: %s", ErrAppExists, appKey.AppID)
+			}
+			return fmt.Errorf("创建应用密钥失败: %w", err)
+		}
+
+		// 插入成功后再检查，并发清除同一应用ID时插入会等待清除提交，之后可以看到记录
+		var purged bool
+		err = tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM app_tombstones WHERE app_id = $1)`, appKey.AppID).Scan(&purged)
+		if err != nil {
+			return fmt.Errorf("查询已清除的应用失败: %w", err)
+		}
+		if purged {
+			return fmt.Errorf("%w: %s", ErrAppPurged, appKey.AppID)
+		}
+
+		for i := range appKey.Secrets {
+			if err := p.insertSecret(ctx, tx, appKey.AppID, &appKey.Secrets[i]); err != nil {
+				return err
+			}
+		}
+		if err := p.resealAppKey(ctx, tx, appKey.AppID); err != nil {
+			return err
+		}
+		return p.recordEvent(ctx, tx, appKey.AppID, ActionCreate, nil, appKey.CreateAt)
+	})
 }
 
-// CreateAppKey 创建应用密钥
-func (s *SignatureSDK) CreateAppKey(appID, secretKey string, ipsWhite []string, attributes map[string]interface{}) error {
-	ipsWhiteJSON, err := json.Marshal(ipsWhite)
+// UpdateAppKey 更新应用的密钥、IP白名单、状态和Attributes，密钥替换当前的主密钥
+func (p *PostgresKeyStore) UpdateAppKey(ctx context.Context, appKey *AppKey) error {
+	ipsWhiteJSON, err := json.Marshal(appKey.IPsWhite)
 	if err != nil {
 		return fmt.Errorf("序列化IP白名单失败: %w", err)
 	}
 
+	secretKey, keyVersion, err := p.sealSecret(ctx, appKey.SecretKey, appKeyAAD(appKey.AppID))
+	if err != nil {
+		return err
+	}
+
+	return p.mutate(ctx, appKey.AppID, ActionUpdate, func(ctx context.Context, tx *sql.Tx) error {
+		query := `
+		UPDATE app_keys 
+		SET secret_key = $2, ips_white = $3, status = $4, update_at = $5, attributes = $6, key_version = $7,
+		    version = version + 1
+		WHERE app_id = $1
+	`
+		now := time.Now().Unix()
+		if _, err := transitionStatus(ctx, tx, appKey.AppID, contextStatusChange(ctx, appKey.Status, appKey.StatusReason), now); err != nil {
+			return err
+		}
+
+		d, _ := json.Marshal(appKey.Attributes)
+		result, err := tx.ExecContext(ctx, query, appKey.AppID, secretKey, ipsWhiteJSON, appKey.Status, now, d, keyVersion)
+		if err != nil {
+			return fmt.Errorf("更新应用密钥失败: %w", err)
+		}
+		if err := checkRowsAffected(result); err != nil {
+			return err
+		}
+		return p.replacePrimarySecret(ctx, tx, appKey.AppID, appKey.SecretKey, now)
+	})
+}
+
+// PatchAppKey 在一条语句中修改 patch 中非空的字段，返回新的版本号
+func (p *PostgresKeyStore) PatchAppKey(ctx context.Context, appID string, patch *AppKeyPatch) (int64, error) {
+	now := time.Now().Unix()
+	sets := []string{"update_at = $2", "version = version + 1"}
+	args := []interface{}{appID, now}
+	set := func(column string, value interface{}) {
+		args = append(args, value)
+		sets = append(sets, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+
+	if patch.SecretKey != nil {
+		secretKey, keyVersion, err := p.sealSecret(ctx, *patch.SecretKey, appKeyAAD(appID))
+		if err != nil {
+			return 0, err
+		}
+		set("secret_key", secretKey)
+		set("key_version", keyVersion)
+	}
+	if patch.IPsWhite != nil {
+		ipsWhiteJSON, err := json.Marshal(patch.IPsWhite)
+		if err != nil {
+			return 0, fmt.Errorf("序列化IP白名单失败: %w", err)
+		}
+		set("ips_white", string(ipsWhiteJSON))
+	}
+	if patch.Attributes != nil {
+		attributesJSON, err := json.Marshal(patch.Attributes)
+		if err != nil {
+			return 0, fmt.Errorf("序列化Attributes失败: %w", err)
+		}
+		set("attributes", string(attributesJSON))
+	}
+
+	query := `UPDATE app_keys SET ` + strings.Join(sets, ", ") + ` WHERE app_id = $1`
+	if patch.Version != 0 {
+		args = append(args, patch.Version)
+		query += fmt.Sprintf(` AND version = $%d`, len(args))
+	}
+	query += ` RETURNING version`
+
+	var version int64
+	err := p.mutate(ctx, appID, ActionPatch, func(ctx context.Context, tx *sql.Tx) error {
+		if patch.Status != nil {
+			if _, err := transitionStatus(ctx, tx, appID, contextStatusChange(ctx, *patch.Status, patch.StatusReason), now); err != nil {
+				return err
+			}
+		}
+
+		err := tx.QueryRowContext(ctx, query, args...).Scan(&version)
+		if err == sql.ErrNoRows {
+			// 应用行已被锁定，只可能是版本不一致
+			return fmt.Errorf("%w: 期望版本 %d", ErrVersionConflict, patch.Version)
+		}
+		if err != nil {
+			return fmt.Errorf("更新应用密钥失败: %w", err)
+		}
+		if patch.SecretKey != nil {
+			return p.replacePrimarySecret(ctx, tx, appID, *patch.SecretKey, now)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+// SetStatus 修改应用状态并记录原因和操作人
+func (p *PostgresKeyStore) SetStatus(ctx context.Context, appID string, change *StatusChange) error {
+	return p.mutate(ctx, appID, statusAction(change.Status), func(ctx context.Context, tx *sql.Tx) error {
+		now := time.Now().Unix()
+		changed, err := transitionStatus(ctx, tx, appID, change, now)
+		if err != nil || !changed {
+			return err
+		}
+		return p.touchAppKey(ctx, tx, appID, "")
+	})
+}
+
+// RestoreAppKey 恢复删除期限内的应用
+func (p *PostgresKeyStore) RestoreAppKey(ctx context.Context, appID string, deletedAfter int64, change *StatusChange) error {
+	return p.mutate(ctx, appID, ActionRestore, func(ctx context.Context, tx *sql.Tx) error {
+		var appKey AppKey
+		err := tx.QueryRowContext(ctx, `SELECT status, deleted_at FROM app_keys WHERE app_id = $1`, appID).
+			Scan(&appKey.Status, &appKey.DeletedAt)
+		if err != nil {
+			return fmt.Errorf("查询应用状态失败: %w", err)
+		}
+		now := time.Now().Unix()
+		if err := restoreAppKey(&appKey, deletedAfter, change, now); err != nil {
+			return err
+		}
+
+		query := `
+		UPDATE app_keys
+		SET status = $2, status_reason = $3, status_actor = $4, status_changed_at = $5, deleted_at = 0
+		WHERE app_id = $1
+	`
+		if _, err := tx.ExecContext(ctx, query, appID, change.Status, change.Reason, change.Actor, now); err != nil {
+			return fmt.Errorf("恢复应用失败: %w", err)
+		}
+		return p.touchAppKey(ctx, tx, appID, "")
+	})
+}
+
+// PurgeDeleted 在一条语句中删除应用（app_secrets 级联删除），写入 app_tombstones 和审计记录
+func (p *PostgresKeyStore) PurgeDeleted(ctx context.Context, deletedBefore, now int64) ([]string, error) {
+	ctx, cancel := withQueryTimeout(ctx, p.QueryTimeout)
+	defer cancel()
+
 	query := `
-		INSERT INTO app_keys (app_id, secret_key, ips_white, status, create_at, attributes)
-		VALUES ($1, $2, $3, 1, $4, $5)
+		WITH purged AS (
+			DELETE FROM app_keys
+			WHERE status = $1 AND deleted_at > 0 AND deleted_at <= $2
+			RETURNING app_id, deleted_at
+		), events AS (
+			INSERT INTO app_key_events (app_id, action, actor, create_at)
+			SELECT app_id, $4, $5, $3 FROM purged
+		)
+		INSERT INTO app_tombstones (app_id, deleted_at, purged_at)
+		SELECT app_id, deleted_at, $3 FROM purged
+		ON CONFLICT (app_id) DO NOTHING
+		RETURNING app_id
 	`
-	d, _ := json.Marshal(attributes)
-	_, err = s.db.Exec(query, appID, secretKey, ipsWhiteJSON, time.Now().Unix(), d)
+	appIDs, err := queryStrings(ctx, p.db, query, int64(StatusDeleted), deletedBefore, now,
+		ActionPurge, actorFromContext(ctx))
 	if err != nil {
-		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
-			return fmt.Errorf("应用ID已存在: %s", appID)
+		return nil, fmt.Errorf("清除已删除的应用失败: %w", err)
+	}
+	sort.Strings(appIDs)
+	return appIDs, nil
+}
+
+// AddWhitelistIPs 使用JSONB运算在一条语句中追加白名单中不存在的条目
+func (p *PostgresKeyStore) AddWhitelistIPs(ctx context.Context, appID string, ips []string) error {
+	ipsJSON, err := json.Marshal(ips)
+	if err != nil {
+		return fmt.Errorf("序列化IP白名单失败: %w", err)
+	}
+
+	return p.mutate(ctx, appID, ActionWhitelist, func(ctx context.Context, tx *sql.Tx) error {
+		query := `
+		UPDATE app_keys
+		SET ips_white = ips_white || (
+		        SELECT COALESCE(jsonb_agg(e.ip ORDER BY e.i), '[]'::jsonb)
+		        FROM jsonb_array_elements($2::jsonb) WITH ORDINALITY AS e(ip, i)
+		        WHERE NOT app_keys.ips_white @> jsonb_build_array(e.ip)),
+		    update_at = $3, version = version + 1
+		WHERE app_id = $1
+	`
+		if _, err := tx.ExecContext(ctx, query, appID, string(ipsJSON), time.Now().Unix()); err != nil {
+			return fmt.Errorf("更新IP白名单失败: %w", err)
 		}
-		return fmt.Errorf("创建应用密钥失败: %w", err)
+		return nil
+	})
+}
+
+// RemoveWhitelistIPs 使用JSONB运算在一条语句中删除白名单条目，删除后为空时不更新并返回 ErrEmptyWhitelist
+func (p *PostgresKeyStore) RemoveWhitelistIPs(ctx context.Context, appID string, ips []string) error {
+	ipsJSON, err := json.Marshal(ips)
+	if err != nil {
+		return fmt.Errorf("序列化IP白名单失败: %w", err)
 	}
 
+	return p.mutate(ctx, appID, ActionWhitelist, func(ctx context.Context, tx *sql.Tx) error {
+		query := `
+		UPDATE app_keys
+		SET ips_white = (
+		        SELECT COALESCE(jsonb_agg(e.ip ORDER BY e.i), '[]'::jsonb)
+		        FROM jsonb_array_elements(app_keys.ips_white) WITH ORDINALITY AS e(ip, i)
+		        WHERE NOT $2::jsonb @> jsonb_build_array(e.ip)),
+		    update_at = $3, version = version + 1
+		WHERE app_id = $1
+		  AND (jsonb_array_length(ips_white) = 0 OR EXISTS (
+		        SELECT 1 FROM jsonb_array_elements(app_keys.ips_white) AS e(ip)
+		        WHERE NOT $2::jsonb @> jsonb_build_array(e.ip)))
+	`
+		result, err := tx.ExecContext(ctx, query, appID, string(ipsJSON), time.Now().Unix())
+		if err != nil {
+			return fmt.Errorf("更新IP白名单失败: %w", err)
+		}
+		// 应用行已由 mutate 锁定，没有更新说明删除后白名单为空
+		if n, err := result.RowsAffected(); err == nil && n == 0 {
+			return ErrEmptyWhitelist
+		}
+		return nil
+	})
+}
+
+// MergeAttributes 使用JSONB运算在一条语句中合并Attributes，值为 nil 的键会被删除
+func (p *PostgresKeyStore) MergeAttributes(ctx context.Context, appID string, attributes map[string]interface{}) error {
+	set := make(map[string]interface{}, len(attributes))
+	removed := []string{}
+	for k, v := range attributes {
+		if v == nil {
+			removed = append(removed, k)
+		} else {
+			set[k] = v
+		}
+	}
+	setJSON, err := json.Marshal(set)
+	if err != nil {
+		return fmt.Errorf("序列化Attributes失败: %w", err)
+	}
+
+	return p.mutate(ctx, appID, ActionAttributes, func(ctx context.Context, tx *sql.Tx) error {
+		query := `
+		UPDATE app_keys
+		SET attributes = (COALESCE(attributes, '{}'::jsonb) || $2::jsonb) - $3::text[],
+		    update_at = $4, version = version + 1
+		WHERE app_id = $1
+	`
+		if _, err := tx.ExecContext(ctx, query, appID, string(setJSON), pq.Array(removed), time.Now().Unix()); err != nil {
+			return fmt.Errorf("更新Attributes失败: %w", err)
+		}
+		return nil
+	})
+}
+
+// SetSignTypes 设置应用的签名算法
+func (p *PostgresKeyStore) SetSignTypes(ctx context.Context, appID string, signType SignType, allowed []SignType) error {
+	if allowed == nil {
+		allowed = []SignType{}
+	}
+	allowedJSON, err := json.Marshal(allowed)
+	if err != nil {
+		return fmt.Errorf("序列化签名算法失败: %w", err)
+	}
+
+	return p.mutate(ctx, appID, ActionSignTypes, func(ctx context.Context, tx *sql.Tx) error {
+		query := `
+		UPDATE app_keys 
+		SET sign_type = $2, allowed_sign_types = $3, update_at = $4, version = version + 1
+		WHERE app_id = $1
+	`
+		if _, err := tx.ExecContext(ctx, query, appID, signType, allowedJSON, time.Now().Unix()); err != nil {
+			return fmt.Errorf("更新签名算法失败: %w", err)
+		}
+		return nil
+	})
+}
+
+// SetPublicKey 设置应用验签使用的PEM公钥
+func (p *PostgresKeyStore) SetPublicKey(ctx context.Context, appID, publicKey string) error {
+	return p.mutate(ctx, appID, ActionPublicKey, func(ctx context.Context, tx *sql.Tx) error {
+		query := `
+		UPDATE app_keys 
+		SET public_key = $2, update_at = $3, version = version + 1
+		WHERE app_id = $1
+	`
+		if _, err := tx.ExecContext(ctx, query, appID, publicKey, time.Now().Unix()); err != nil {
+			return fmt.Errorf("更新公钥失败: %w", err)
+		}
+		return nil
+	})
+}
+
+// AddSecret 添加密钥，Primary 为true时同时更新主密钥
+func (p *PostgresKeyStore) AddSecret(ctx context.Context, appID string, secret *AppSecret) error {
+	return p.mutate(ctx, appID, ActionAddSecret, func(ctx context.Context, tx *sql.Tx) error {
+		if secret.Primary {
+			if _, err := tx.ExecContext(ctx, `UPDATE app_secrets SET is_primary = FALSE WHERE app_id = $1 AND is_primary`, appID); err != nil {
+				return fmt.Errorf("更新主密钥失败: %w", err)
+			}
+		}
+		if err := p.insertSecret(ctx, tx, appID, secret); err != nil {
+			return err
+		}
+		if secret.Primary {
+			return p.touchAppKey(ctx, tx, appID, secret.Secret)
+		}
+		return p.touchAppKey(ctx, tx, appID, "")
+	})
+}
+
+// RemoveSecret 删除密钥，不能删除主密钥
+func (p *PostgresKeyStore) RemoveSecret(ctx context.Context, appID, keyID string) error {
+	return p.mutate(ctx, appID, ActionRemoveSecret, func(ctx context.Context, tx *sql.Tx) error {
+		var primary bool
+		err := tx.QueryRowContext(ctx, `SELECT is_primary FROM app_secrets WHERE app_id = $1 AND key_id = $2`,
+			appID, keyID).Scan(&primary)
+		if err == sql.ErrNoRows {
+			return ErrSecretNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("查询密钥失败: %w", err)
+		}
+		if primary {
+			return ErrPrimarySecret
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM app_secrets WHERE app_id = $1 AND key_id = $2`, appID, keyID); err != nil {
+			return fmt.Errorf("删除密钥失败: %w", err)
+		}
+		return p.touchAppKey(ctx, tx, appID, "")
+	})
+}
+
+// SetPrimarySecret 设置主密钥
+func (p *PostgresKeyStore) SetPrimarySecret(ctx context.Context, appID, keyID string) error {
+	return p.mutate(ctx, appID, ActionPrimarySecret, func(ctx context.Context, tx *sql.Tx) error {
+		var secret, keyVersion string
+		err := tx.QueryRowContext(ctx, `SELECT secret, key_version FROM app_secrets WHERE app_id = $1 AND key_id = $2`,
+			appID, keyID).Scan(&secret, &keyVersion)
+		if err == sql.ErrNoRows {
+			return ErrSecretNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("查询密钥失败: %w", err)
+		}
+		if secret, err = p.openSecret(ctx, secret, keyVersion, appSecretAAD(appID, keyID)); err != nil {
+			return err
+		}
+
+		// 部分唯一索引逐行检查，需先取消原主密钥
+		if _, err := tx.ExecContext(ctx, `UPDATE app_secrets SET is_primary = FALSE WHERE app_id = $1 AND is_primary`, appID); err != nil {
+			return fmt.Errorf("更新主密钥失败: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE app_secrets SET is_primary = TRUE WHERE app_id = $1 AND key_id = $2`, appID, keyID); err != nil {
+			return fmt.Errorf("更新主密钥失败: %w", err)
+		}
+		return p.touchAppKey(ctx, tx, appID, secret)
+	})
+}
+
+// RotateSecret 将 secret 设为主密钥，原主密钥最晚在 notAfter 失效
+func (p *PostgresKeyStore) RotateSecret(ctx context.Context, appID string, secret *AppSecret, notAfter int64) (string, error) {
+	var previousKeyID string
+	err := p.mutate(ctx, appID, ActionRotateSecret, func(ctx context.Context, tx *sql.Tx) error {
+		query := `
+		UPDATE app_secrets
+		SET is_primary = FALSE,
+		    not_after = CASE WHEN not_after = 0 OR not_after > $2 THEN $2 ELSE not_after END
+		WHERE app_id = $1 AND is_primary
+		RETURNING key_id
+	`
+		err := tx.QueryRowContext(ctx, query, appID, notAfter).Scan(&previousKeyID)
+		if err != nil && err != sql.ErrNoRows {
+			return fmt.Errorf("更新主密钥失败: %w", err)
+		}
+
+		if err := p.insertSecret(ctx, tx, appID, secret); err != nil {
+			return err
+		}
+		return p.touchAppKey(ctx, tx, appID, secret.Secret)
+	})
+	return previousKeyID, err
+}
+
+// DeleteExpiredSecrets 删除全部应用中已失效的非主密钥
+func (p *PostgresKeyStore) DeleteExpiredSecrets(ctx context.Context, now int64) ([]RetiredSecret, error) {
+	var retired []RetiredSecret
+	err := p.withTx(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		// 先按顺序锁定应用行，与其他修改保持相同的加锁顺序
+		query := `
+		SELECT app_id FROM app_keys
+		WHERE app_id IN (
+			SELECT app_id FROM app_secrets
+			WHERE NOT is_primary AND not_after > 0 AND not_after <= $1)
+		ORDER BY app_id
+		FOR UPDATE
+	`
+		appIDs, err := queryStrings(ctx, tx, query, now)
+		if err != nil {
+			return fmt.Errorf("查询过期密钥失败: %w", err)
+		}
+		if len(appIDs) == 0 {
+			return nil
+		}
+
+		before := make(map[string]*AppKey, len(appIDs))
+		for _, appID := range appIDs {
+			if before[appID], _, err = p.readAppKey(ctx, tx, appID, false); err != nil {
+				return err
+			}
+		}
+
+		query = `
+		DELETE FROM app_secrets
+		WHERE NOT is_primary AND not_after > 0 AND not_after <= $1 AND app_id = ANY($2)
+		RETURNING app_id, key_id
+	`
+		rows, err := tx.QueryContext(ctx, query, now, pq.Array(appIDs))
+		if err != nil {
+			return fmt.Errorf("删除过期密钥失败: %w", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var r RetiredSecret
+			if err := rows.Scan(&r.AppID, &r.KeyID); err != nil {
+				return fmt.Errorf("删除过期密钥失败: %w", err)
+			}
+			retired = append(retired, r)
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("删除过期密钥失败: %w", err)
+		}
+		rows.Close()
+
+		for _, appID := range appIDs {
+			if err := p.touchAppKey(ctx, tx, appID, ""); err != nil {
+				return err
+			}
+			if err := p.resealAppKey(ctx, tx, appID); err != nil {
+				return err
+			}
+			if err := p.recordEvent(ctx, tx, appID, ActionExpireSecrets, before[appID], now); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return retired, nil
+}
+
+// withQueryTimeout 为 ctx 设置超时时间，timeout 为0时直接返回 ctx
+func withQueryTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// snapshotTxOptions 只读的可重复读事务，事务中的查询读取同一快照
+var snapshotTxOptions = &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+
+// withTx 在事务中执行 fn，fn 返回错误时回滚；fn 需使用传入的 ctx，事务受 QueryTimeout 限制
+func (p *PostgresKeyStore) withTx(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error) error {
+	return p.withTxOptions(ctx, nil, fn)
+}
+
+// withSnapshot 在只读快照事务中执行 fn
+// 分多条语句读取应用行和 app_secrets 时需要使用它，避免分别读到密钥轮换前后的数据而误判 row_mac
+func (p *PostgresKeyStore) withSnapshot(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error) error {
+	return p.withTxOptions(ctx, snapshotTxOptions, fn)
+}
+
+func (p *PostgresKeyStore) withTxOptions(ctx context.Context, opts *sql.TxOptions, fn func(ctx context.Context, tx *sql.Tx) error) error {
+	ctx, cancel := withQueryTimeout(ctx, p.QueryTimeout)
+	defer cancel()
+
+	tx, err := p.db.BeginTx(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("开始事务失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(ctx, tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("提交事务失败: %w", err)
+	}
 	return nil
 }
 
-// UpdateAppKey 更新应用密钥
-func (s *SignatureSDK) UpdateAppKey(appID, secretKey string, ipsWhite []string, status int, attributes map[string]interface{}) error {
-	ipsWhiteJSON, err := json.Marshal(ipsWhite)
+// mutate 在事务中锁定应用行后执行 fn，重新计算 row_mac 并写入审计记录
+// 修改 app_keys 或 app_secrets 都需要通过它，保证 row_mac 和审计记录与数据一致
+func (p *PostgresKeyStore) mutate(ctx context.Context, appID string, action AppKeyAction, fn func(ctx context.Context, tx *sql.Tx) error) error {
+	return p.withTx(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		// 锁定应用行并读取修改前的数据
+		before, _, err := p.readAppKey(ctx, tx, appID, true)
+		if err != nil {
+			return err
+		}
+		if err := fn(ctx, tx); err != nil {
+			return err
+		}
+		if err := p.resealAppKey(ctx, tx, appID); err != nil {
+			return err
+		}
+		return p.recordEvent(ctx, tx, appID, action, before, time.Now().Unix())
+	})
+}
+
+// recordEvent 读取事务中的最新数据，与 before 比较后写入审计记录，before 为 nil 表示创建
+func (p *PostgresKeyStore) recordEvent(ctx context.Context, tx *sql.Tx, appID string, action AppKeyAction, before *AppKey, now int64) error {
+	after, _, err := p.readAppKey(ctx, tx, appID, false)
 	if err != nil {
-		return fmt.Errorf("序列化IP白名单失败: %w", err)
+		return err
+	}
+	return insertEvent(ctx, tx, newAppKeyEvent(ctx, action, before, after, now))
+}
+
+// insertEvent 写入审计记录，event 为 nil 时不做任何操作
+func insertEvent(ctx context.Context, tx *sql.Tx, event *AppKeyEvent) error {
+	if event == nil {
+		return nil
+	}
+	changesJSON, err := json.Marshal(event.Changes)
+	if err != nil {
+		return fmt.Errorf("序列化审计记录失败: %w", err)
+	}
+	var snapshotJSON interface{}
+	if event.Snapshot != nil {
+		data, err := json.Marshal(event.Snapshot)
+		if err != nil {
+			return fmt.Errorf("序列化审计记录失败: %w", err)
+		}
+		snapshotJSON = string(data)
 	}
 
 	query := `
-		UPDATE app_keys 
-		SET secret_key = $2, ips_white = $3, status = $4, update_at = $5, attributes = $6
+		INSERT INTO app_key_events (app_id, action, actor, create_at, changes, snapshot)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	_, err = tx.ExecContext(ctx, query, event.AppID, event.Action, event.Actor, event.CreateAt, string(changesJSON), snapshotJSON)
+	if err != nil {
+		return fmt.Errorf("写入审计记录失败: %w", err)
+	}
+	return nil
+}
+
+// History 查询应用的全部审计记录
+func (p *PostgresKeyStore) History(ctx context.Context, appID string) ([]AppKeyEvent, error) {
+	ctx, cancel := withQueryTimeout(ctx, p.QueryTimeout)
+	defer cancel()
+
+	query := `
+		SELECT id, app_id, action, actor, create_at, changes, snapshot
+		FROM app_key_events
 		WHERE app_id = $1
+		ORDER BY id
 	`
-	d, _ := json.Marshal(attributes)
-	result, err := s.db.Exec(query, appID, secretKey, ipsWhiteJSON, status, time.Now().Unix(), d)
+	rows, err := p.db.QueryContext(ctx, query, appID)
 	if err != nil {
-		return fmt.Errorf("更新应用密钥失败: %w", err)
+		return nil, fmt.Errorf("查询审计记录失败: %w", err)
 	}
+	defer rows.Close()
 
+	events := []AppKeyEvent{}
+	for rows.Next() {
+		var event AppKeyEvent
+		var changesJSON, snapshotJSON []byte
+		if err := rows.Scan(&event.ID, &event.AppID, &event.Action, &event.Actor, &event.CreateAt,
+			&changesJSON, &snapshotJSON); err != nil {
+			return nil, fmt.Errorf("查询审计记录失败: %w", err)
+		}
+		if err := json.Unmarshal(changesJSON, &event.Changes); err != nil {
+			return nil, fmt.Errorf("解析审计记录失败: %w", err)
+		}
+		if snapshotJSON != nil {
+			if err := json.Unmarshal(snapshotJSON, &event.Snapshot); err != nil {
+				return nil, fmt.Errorf("解析审计记录失败: %w", err)
+			}
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("查询审计记录失败: %w", err)
+	}
+	return events, nil
+}
+
+// GetAppKeyAt 返回 at 时刻（含）之前最后一条审计记录中的快照
+func (p *PostgresKeyStore) GetAppKeyAt(ctx context.Context, appID string, at int64) (*AppKey, error) {
+	ctx, cancel := withQueryTimeout(ctx, p.QueryTimeout)
+	defer cancel()
+
+	query := `
+		SELECT snapshot FROM app_key_events
+		WHERE app_id = $1 AND create_at <= $2
+		ORDER BY id DESC
+		LIMIT 1
+	`
+	var snapshotJSON []byte
+	err := p.db.QueryRowContext(ctx, query, appID, at).Scan(&snapshotJSON)
+	if err == sql.ErrNoRows || (err == nil && snapshotJSON == nil) {
+		return nil, ErrAppNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询审计记录失败: %w", err)
+	}
+
+	var appKey AppKey
+	if err := json.Unmarshal(snapshotJSON, &appKey); err != nil {
+		return nil, fmt.Errorf("解析审计记录失败: %w", err)
+	}
+	return &appKey, nil
+}
+
+// resealAppKey 根据事务中的最新数据重新计算应用的 row_mac，未配置 RowMACKey 时不做任何操作
+func (p *PostgresKeyStore) resealAppKey(ctx context.Context, tx *sql.Tx, appID string) error {
+	if len(p.RowMACKey) == 0 {
+		return nil
+	}
+
+	appKey, _, err := p.readAppKey(ctx, tx, appID, true)
+	if err != nil {
+		return err
+	}
+	mac := computeRowMAC(p.RowMACKey, appKey)
+	if _, err := tx.ExecContext(ctx, `UPDATE app_keys SET row_mac = $2 WHERE app_id = $1`, appID, mac); err != nil {
+		return fmt.Errorf("更新row_mac失败: %w", err)
+	}
+	return nil
+}
+
+// transitionStatus 校验并记录状态变更，调用方需已锁定应用行；状态不变时返回false
+func transitionStatus(ctx context.Context, tx *sql.Tx, appID string, change *StatusChange, now int64) (bool, error) {
+	var from AppStatus
+	err := tx.QueryRowContext(ctx, `SELECT status FROM app_keys WHERE app_id = $1`, appID).Scan(&from)
+	if err == sql.ErrNoRows {
+		return false, ErrAppNotFound
+	}
+	if err != nil {
+		return false, fmt.Errorf("查询应用状态失败: %w", err)
+	}
+	if err := checkTransition(from, change.Status); err != nil {
+		return false, err
+	}
+	if from == change.Status {
+		return false, nil
+	}
+
+	var deletedAt int64
+	if change.Status == StatusDeleted {
+		deletedAt = now
+	}
+	query := `
+		UPDATE app_keys
+		SET status = $2, status_reason = $3, status_actor = $4, status_changed_at = $5, deleted_at = $6
+		WHERE app_id = $1
+	`
+	if _, err := tx.ExecContext(ctx, query, appID, change.Status, change.Reason, change.Actor, now, deletedAt); err != nil {
+		return false, fmt.Errorf("更新应用状态失败: %w", err)
+	}
+	return true, nil
+}
+
+// touchAppKey 更新应用的修改时间，primarySecret 非空时同步主密钥
+func (p *PostgresKeyStore) touchAppKey(ctx context.Context, tx *sql.Tx, appID, primarySecret string) error {
+	query := `UPDATE app_keys SET update_at = $2, version = version + 1 WHERE app_id = $1`
+	args := []interface{}{appID, time.Now().Unix()}
+	if primarySecret != "" {
+		sealed, keyVersion, err := p.sealSecret(ctx, primarySecret, appKeyAAD(appID))
+		if err != nil {
+			return err
+		}
+		query = `UPDATE app_keys SET update_at = $2, version = version + 1, secret_key = $3, key_version = $4 WHERE app_id = $1`
+		args = append(args, sealed, keyVersion)
+	}
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("更新应用失败: %w", err)
+	}
+	return nil
+}
+
+// replacePrimarySecret 替换 app_secrets 中主密钥的值，没有密钥记录时补充一条
+func (p *PostgresKeyStore) replacePrimarySecret(ctx context.Context, tx *sql.Tx, appID, secret string, now int64) error {
+	var keyID string
+	err := tx.QueryRowContext(ctx, `SELECT key_id FROM app_secrets WHERE app_id = $1 AND is_primary`, appID).Scan(&keyID)
+	if err == nil {
+		return p.updateSecret(ctx, tx, appID, keyID, secret)
+	}
+	if err != sql.ErrNoRows {
+		return fmt.Errorf("查询主密钥失败: %w", err)
+	}
+
+	// 迁移前创建且没有密钥记录的应用
+	return p.insertSecret(ctx, tx, appID, &AppSecret{KeyID: newKeyID(), Secret: secret, Primary: true, CreateAt: now})
+}
+
+// insertSecret 写入一个密钥，密钥ID重复时返回 ErrSecretExists
+func (p *PostgresKeyStore) insertSecret(ctx context.Context, tx *sql.Tx, appID string, secret *AppSecret) error {
+	sealed, keyVersion, err := p.sealSecret(ctx, secret.Secret, appSecretAAD(appID, secret.KeyID))
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO app_secrets (app_id, key_id, secret, is_primary, not_before, not_after, create_at, key_version)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err = tx.ExecContext(ctx, query, appID, secret.KeyID, sealed, secret.Primary,
+		secret.NotBefore, secret.NotAfter, secret.CreateAt, keyVersion)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return fmt.Errorf("%w: %s", ErrSecretExists, secret.KeyID)
+		}
+		return fmt.Errorf("写入密钥失败: %w", err)
+	}
+	return nil
+}
+
+// updateSecret 替换密钥的值
+func (p *PostgresKeyStore) updateSecret(ctx context.Context, tx *sql.Tx, appID, keyID, secret string) error {
+	sealed, keyVersion, err := p.sealSecret(ctx, secret, appSecretAAD(appID, keyID))
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE app_secrets SET secret = $3, key_version = $4 WHERE app_id = $1 AND key_id = $2`
+	if _, err := tx.ExecContext(ctx, query, appID, keyID, sealed, keyVersion); err != nil {
+		return fmt.Errorf("更新密钥失败: %w", err)
+	}
+	return nil
+}
+
+// queryer 兼容 *sql.DB 和 *sql.Tx
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// queryStrings 查询单列字符串
+func queryStrings(ctx context.Context, q queryer, query string, args ...interface{}) ([]string, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []string
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		result = append(result, v)
+	}
+	return result, rows.Err()
+}
+
+// loadSecrets 查询并解密应用的全部密钥，主密钥在前
+func (p *PostgresKeyStore) loadSecrets(ctx context.Context, q queryer, appID string) ([]AppSecret, error) {
+	secrets, err := p.loadSecretsFor(ctx, q, []string{appID})
+	if err != nil {
+		return nil, err
+	}
+	return secrets[appID], nil
+}
+
+// loadSecretsFor 在一次查询中读取并解密多个应用的全部密钥，按应用ID分组，主密钥在前
+func (p *PostgresKeyStore) loadSecretsFor(ctx context.Context, q queryer, appIDs []string) (map[string][]AppSecret, error) {
+	query := `
+		SELECT app_id, key_id, secret, is_primary, not_before, not_after, create_at, key_version
+		FROM app_secrets
+		WHERE app_id = ANY($1)
+		ORDER BY app_id, is_primary DESC, create_at DESC, id DESC
+	`
+	rows, err := q.QueryContext(ctx, query, pq.Array(appIDs))
+	if err != nil {
+		return nil, fmt.Errorf("查询应用密钥失败: %w", err)
+	}
+	defer rows.Close()
+
+	secrets := make(map[string][]AppSecret, len(appIDs))
+	for rows.Next() {
+		var appID, keyVersion string
+		var secret AppSecret
+		if err := rows.Scan(&appID, &secret.KeyID, &secret.Secret, &secret.Primary, &secret.NotBefore,
+			&secret.NotAfter, &secret.CreateAt, &keyVersion); err != nil {
+			return nil, fmt.Errorf("查询应用密钥失败: %w", err)
+		}
+		if secret.Secret, err = p.openSecret(ctx, secret.Secret, keyVersion, appSecretAAD(appID, secret.KeyID)); err != nil {
+			return nil, err
+		}
+		secrets[appID] = append(secrets[appID], secret)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("查询应用密钥失败: %w", err)
+	}
+	return secrets, nil
+}
+
+// checkRowsAffected 没有行被更新时返回 ErrAppNotFound
+func checkRowsAffected(result sql.Result) error {
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
 		return fmt.Errorf("获取更新行数失败: %w", err)
@@ -110,3 +1205,192 @@ func (s *SignatureSDK) UpdateAppKey(appID, secretKey string, ipsWhite []string,
 
 	return nil
 }
+
+// appKeyAAD app_keys.secret_key 的附加认证数据
+func appKeyAAD(appID string) string {
+	return "app_keys/" + appID
+}
+
+// appSecretAAD app_secrets.secret 的附加认证数据
+func appSecretAAD(appID, keyID string) string {
+	return "app_secrets/" + appID + "/" + keyID
+}
+
+// sealSecret 加密待写入的密钥，未配置 Encryption 时原样返回，版本为空
+func (p *PostgresKeyStore) sealSecret(ctx context.Context, secret, aad string) (string, string, error) {
+	if p.Encryption == nil {
+		return secret, "", nil
+	}
+	return sealSecret(ctx, p.Encryption, secret, aad)
+}
+
+// openSecret 解密读取的密钥，版本为空表示明文
+func (p *PostgresKeyStore) openSecret(ctx context.Context, secret, keyVersion, aad string) (string, error) {
+	if keyVersion == "" {
+		return secret, nil
+	}
+	if p.Encryption == nil {
+		return "", fmt.Errorf("%w: 密钥已加密但未配置 KeyEncryptionProvider", ErrKeyEncryption)
+	}
+	return openSecret(ctx, p.Encryption, secret, keyVersion, aad)
+}
+
+// rewrapBatchSize 每个事务重新加密的记录数
+const rewrapBatchSize = 100
+
+// encryptedColumn 加密存储的列
+type encryptedColumn struct {
+	table  string
+	column string
+	keyID  string // 构造附加认证数据的密钥ID列，为空时使用 appKeyAAD
+}
+
+var encryptedColumns = []encryptedColumn{
+	{table: "app_keys", column: "secret_key"},
+	{table: "app_secrets", column: "secret", keyID: "key_id"},
+}
+
+// RewrapKeys 使用当前主密钥重新加密全部数据密钥，返回更新的记录数
+// 明文存储的记录会被加密；用于轮换主密钥或为已有数据启用加密，可重复执行
+func (p *PostgresKeyStore) RewrapKeys(ctx context.Context) (int, error) {
+	if p.Encryption == nil {
+		return 0, fmt.Errorf("%w: 未配置 KeyEncryptionProvider", ErrKeyEncryption)
+	}
+
+	var total int
+	for _, col := range encryptedColumns {
+		for {
+			n, err := p.rewrapBatch(ctx, col)
+			total += n
+			if err != nil {
+				return total, err
+			}
+			// 并发修改可能使一批不满 rewrapBatchSize，直到没有旧版本的记录才结束
+			if n == 0 {
+				break
+			}
+		}
+	}
+	return total, nil
+}
+
+// rewrapBatch 在一个事务中重新加密一批主密钥版本不是当前版本的记录
+// 被其他事务锁定的记录会等待其提交，不能跳过，否则 RewrapKeys 结束时仍有记录使用旧版本
+func (p *PostgresKeyStore) rewrapBatch(ctx context.Context, col encryptedColumn) (int, error) {
+	current := p.Encryption.CurrentVersion()
+	keyID := "''"
+	if col.keyID != "" {
+		keyID = col.keyID
+	}
+
+	var count int
+	err := p.withTx(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		query := fmt.Sprintf(`
+		SELECT id, app_id, %s, %s, key_version
+		FROM %s
+		WHERE key_version <> $1
+		ORDER BY id
+		LIMIT $2
+		FOR UPDATE
+	`, keyID, col.column, col.table)
+		rows, err := tx.QueryContext(ctx, query, current, rewrapBatchSize)
+		if err != nil {
+			return fmt.Errorf("查询待加密记录失败: %w", err)
+		}
+
+		type record struct {
+			id                              int
+			appID, keyID, value, keyVersion string
+		}
+		var records []record
+		for rows.Next() {
+			var r record
+			if err := rows.Scan(&r.id, &r.appID, &r.keyID, &r.value, &r.keyVersion); err != nil {
+				rows.Close()
+				return fmt.Errorf("查询待加密记录失败: %w", err)
+			}
+			records = append(records, r)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("查询待加密记录失败: %w", err)
+		}
+
+		update := fmt.Sprintf(`UPDATE %s SET %s = $2, key_version = $3 WHERE id = $1`, col.table, col.column)
+		for _, r := range records {
+			var value, version string
+			var err error
+			if r.keyVersion == "" {
+				aad := appKeyAAD(r.appID)
+				if col.keyID != "" {
+					aad = appSecretAAD(r.appID, r.keyID)
+				}
+				value, version, err = sealSecret(ctx, p.Encryption, r.value, aad)
+			} else {
+				value, version, err = rewrapSecret(ctx, p.Encryption, r.value, r.keyVersion)
+			}
+			if err != nil {
+				return fmt.Errorf("重新加密 %s %s 失败: %w", col.table, r.appID, err)
+			}
+			if version != current {
+				return fmt.Errorf("%w: 重新加密期间主密钥版本由 %s 变为 %s", ErrKeyEncryption, current, version)
+			}
+
+			if _, err := tx.ExecContext(ctx, update, r.id, value, version); err != nil {
+				return fmt.Errorf("更新加密记录失败: %w", err)
+			}
+		}
+		count = len(records)
+		return nil
+	})
+	return count, err
+}
+
+// VerifyRows 校验全部应用的 row_mac，返回校验失败的应用
+func (p *PostgresKeyStore) VerifyRows(ctx context.Context) ([]TamperedRow, error) {
+	if len(p.RowMACKey) == 0 {
+		return nil, fmt.Errorf("%w: 未配置 RowMACKey", ErrRowTampered)
+	}
+
+	appIDs, err := queryStrings(ctx, p.db, `SELECT app_id FROM app_keys ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("查询应用失败: %w", err)
+	}
+
+	var tampered []TamperedRow
+	for _, appID := range appIDs {
+		_, err := p.GetAppKey(ctx, appID)
+		if err == ErrAppNotFound {
+			continue
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			tampered = append(tampered, TamperedRow{AppID: appID, Reason: err.Error()})
+		}
+	}
+	return tampered, nil
+}
+
+// SealRows 为 row_mac 为空的应用计算 row_mac，返回更新的记录数
+// 用于为启用校验前已存在的数据补充 row_mac，执行前应确认数据未被篡改
+func (p *PostgresKeyStore) SealRows(ctx context.Context) (int, error) {
+	if len(p.RowMACKey) == 0 {
+		return 0, fmt.Errorf("%w: 未配置 RowMACKey", ErrRowTampered)
+	}
+
+	appIDs, err := queryStrings(ctx, p.db, `SELECT app_id FROM app_keys WHERE row_mac = '' ORDER BY id`)
+	if err != nil {
+		return 0, fmt.Errorf("查询应用失败: %w", err)
+	}
+	for i, appID := range appIDs {
+		err := p.withTx(ctx, func(ctx context.Context, tx *sql.Tx) error {
+			return p.resealAppKey(ctx, tx, appID)
+		})
+		if err != nil && err != ErrAppNotFound {
+			return i, err
+		}
+	}
+	return len(appIDs), nil
+}
@@ -0,0 +1,55 @@
+package go_signature_sdk
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DefaultDeleteRetention 删除后可以恢复的默认期限
+const DefaultDeleteRetention = 30 * 24 * time.Hour
+
+// tombstone 已清除的应用ID
+type tombstone struct {
+	AppID     string `json:"app_id"`
+	DeletedAt int64  `json:"deleted_at"`
+	PurgedAt  int64  `json:"purged_at"`
+}
+
+// restoreAppKey 校验恢复期限并恢复应用
+func restoreAppKey(appKey *AppKey, deletedAfter int64, change *StatusChange, now int64) error {
+	if appKey.Status != StatusDeleted {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, appKey.Status, change.Status)
+	}
+	if appKey.DeletedAt < deletedAfter {
+		return ErrRestoreExpired
+	}
+	appKey.Status = change.Status
+	appKey.StatusReason = change.Reason
+	appKey.StatusActor = change.Actor
+	appKey.StatusChangedAt = now
+	appKey.DeletedAt = 0
+	return nil
+}
+
+// DeleteAppKey 软删除应用，删除后验签立即返回 ErrAppDeleted
+// 在 Config.DeleteRetention 内可以通过 RestoreAppKey 恢复，之后由 PurgeDeleted 清除
+func (s *SignatureSDK) DeleteAppKey(ctx context.Context, appID, reason, actor string) error {
+	change := &StatusChange{Status: StatusDeleted, Reason: reason, Actor: actor}
+	return s.store.SetStatus(withChangeActor(ctx, change), appID, change)
+}
+
+// RestoreAppKey 恢复删除期限内的应用，恢复后为 StatusSuspended，确认无误后再通过 SetStatus 启用
+// 应用未删除时返回 ErrInvalidTransition，超过期限返回 ErrRestoreExpired
+func (s *SignatureSDK) RestoreAppKey(ctx context.Context, appID, reason, actor string) error {
+	deletedAfter := s.now().Add(-s.deleteRetention).Unix()
+	change := &StatusChange{Status: StatusSuspended, Reason: reason, Actor: actor}
+	return s.store.RestoreAppKey(withChangeActor(ctx, change), appID, deletedAfter, change)
+}
+
+// PurgeDeleted 清除删除超过 olderThan 的应用及其全部密钥，返回被清除的应用ID
+// 被清除的应用ID会保留记录，再次创建时返回 ErrAppPurged
+func (s *SignatureSDK) PurgeDeleted(ctx context.Context, olderThan time.Duration) ([]string, error) {
+	now := s.now()
+	return s.store.PurgeDeleted(ctx, now.Add(-olderThan).Unix(), now.Unix())
+}
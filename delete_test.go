@@ -0,0 +1,133 @@
+package go_signature_sdk
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestDeleteAppKey 测试内存存储的软删除、恢复和清除
+func TestDeleteAppKey(t *testing.T) {
+	testDeleteAppKey(t, func(config *Config) *SignatureSDK {
+		config.KeyStore = NewMemoryKeyStore()
+		return NewSignatureSDK(config)
+	})
+}
+
+// TestSDKDeleteAppKey 测试PostgreSQL存储的软删除、恢复和清除
+func TestSDKDeleteAppKey(t *testing.T) {
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+	testDeleteAppKey(t, func(config *Config) *SignatureSDK {
+		config.DB = db
+		sdk, err := New(config)
+		if err != nil {
+			t.Fatalf("创建SDK失败: %v", err)
+		}
+		return sdk
+	})
+}
+
+func testDeleteAppKey(t *testing.T, newSDK func(config *Config) *SignatureSDK) {
+	ctx := context.Background()
+	offset := time.Duration(0)
+	sdk := newSDK(&Config{
+		DeleteRetention: time.Hour,
+		Clock:           func() time.Time { return time.Now().Add(offset) },
+	})
+
+	for _, appID := range []string{"test_app_delete", "test_app_purge"} {
+		if err := sdk.CreateAppKey(appID, "test_secret", []string{"127.0.0.1"}, nil); err != nil {
+			t.Fatalf("创建测试应用失败: %v", err)
+		}
+	}
+	if err := sdk.DeleteAppKey(ctx, "test_app_delete", "合作终止", "alice"); err != nil {
+		t.Fatalf("删除应用失败: %v", err)
+	}
+	if _, err := sdk.VerifyIPs("test_app_delete", "127.0.0.1"); !errors.Is(err, ErrAppDeleted) {
+		t.Errorf("期望已删除错误, 实际: %v", err)
+	}
+	appKey, _ := sdk.GetAppKey("test_app_delete")
+	if appKey.DeletedAt == 0 || appKey.StatusReason != "合作终止" {
+		t.Errorf("删除记录错误: %+v", appKey)
+	}
+	page, _ := sdk.ListAppKeys(ctx, AppKeyFilter{})
+	if len(page.AppKeys) != 1 || page.AppKeys[0].AppID != "test_app_purge" {
+		t.Errorf("列表默认不应包含已删除的应用: %+v", page.AppKeys)
+	}
+	if page, _ := sdk.ListAppKeys(ctx, AppKeyFilter{IncludeDeleted: true}); len(page.AppKeys) != 2 {
+		t.Errorf("IncludeDeleted 应包含已删除的应用: %+v", page.AppKeys)
+	}
+
+	// 恢复后为暂停状态
+	if err := sdk.RestoreAppKey(ctx, "test_app_delete", "误删", "bob"); err != nil {
+		t.Fatalf("恢复应用失败: %v", err)
+	}
+	appKey, _ = sdk.GetAppKey("test_app_delete")
+	if appKey.Status != StatusSuspended || appKey.DeletedAt != 0 {
+		t.Errorf("恢复后状态错误: %+v", appKey)
+	}
+	if err := sdk.RestoreAppKey(ctx, "test_app_delete", "", ""); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("期望状态变更错误, 实际: %v", err)
+	}
+
+	// 超过恢复期限
+	for _, appID := range []string{"test_app_delete", "test_app_purge"} {
+		if err := sdk.DeleteAppKey(ctx, appID, "", ""); err != nil {
+			t.Fatalf("删除应用失败: %v", err)
+		}
+	}
+	offset = 2 * time.Hour
+	if err := sdk.RestoreAppKey(ctx, "test_app_delete", "", ""); !errors.Is(err, ErrRestoreExpired) {
+		t.Errorf("期望超过恢复期限错误, 实际: %v", err)
+	}
+
+	purged, err := sdk.PurgeDeleted(ctx, 3*time.Hour)
+	if err != nil || len(purged) != 0 {
+		t.Errorf("不应清除删除时间较短的应用: %v, %v", purged, err)
+	}
+	purged, err = sdk.PurgeDeleted(ctx, time.Hour)
+	if err != nil || len(purged) != 2 || purged[0] != "test_app_delete" {
+		t.Fatalf("清除应用失败: %v, %v", purged, err)
+	}
+	if _, err := sdk.GetAppKey("test_app_purge"); !errors.Is(err, ErrAppNotFound) {
+		t.Errorf("期望应用不存在错误, 实际: %v", err)
+	}
+
+	// 已清除的应用ID不能重新创建
+	err = sdk.CreateAppKey("test_app_purge", "test_secret", nil, nil)
+	if !errors.Is(err, ErrAppPurged) || !errors.Is(err, ErrAppExists) {
+		t.Errorf("期望应用ID已清除错误, 实际: %v", err)
+	}
+}
+
+// TestFileKeyStoreTombstones 测试文件存储保留已清除的应用ID
+func TestFileKeyStoreTombstones(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "keys.json")
+	store, err := NewFileKeyStore(path)
+	if err != nil {
+		t.Fatalf("创建文件存储失败: %v", err)
+	}
+	sdk := NewSignatureSDK(&Config{KeyStore: store})
+	if err := sdk.CreateAppKey("test_app", "test_secret", nil, nil); err != nil {
+		t.Fatalf("创建测试应用失败: %v", err)
+	}
+	if err := sdk.DeleteAppKey(ctx, "test_app", "", ""); err != nil {
+		t.Fatalf("删除应用失败: %v", err)
+	}
+	if purged, err := sdk.PurgeDeleted(ctx, 0); err != nil || len(purged) != 1 {
+		t.Fatalf("清除应用失败: %v, %v", purged, err)
+	}
+
+	reopened, err := NewFileKeyStore(path)
+	if err != nil {
+		t.Fatalf("重新打开文件存储失败: %v", err)
+	}
+	sdk = NewSignatureSDK(&Config{KeyStore: reopened})
+	if err := sdk.CreateAppKey("test_app", "test_secret", nil, nil); !errors.Is(err, ErrAppPurged) {
+		t.Errorf("期望应用ID已清除错误, 实际: %v", err)
+	}
+}
@@ -0,0 +1,250 @@
+package go_signature_sdk
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// KeyEncryptionProvider 主密钥提供者，用于加密（wrap）每条记录的数据密钥
+// 可以基于本地文件、KMS 或 HSM 实现；主密钥本身不会离开提供者
+type KeyEncryptionProvider interface {
+	// CurrentVersion 返回加密新数据密钥使用的主密钥版本
+	CurrentVersion() string
+	// WrapKey 使用当前主密钥加密数据密钥，返回密文和主密钥版本
+	WrapKey(ctx context.Context, dataKey []byte) (wrapped []byte, version string, err error)
+	// UnwrapKey 使用指定版本的主密钥解密数据密钥
+	UnwrapKey(ctx context.Context, wrapped []byte, version string) ([]byte, error)
+}
+
+// dataKeySize 数据密钥长度，使用 AES-256-GCM
+const dataKeySize = 32
+
+// LocalKeyProvider 使用本地主密钥的 KeyEncryptionProvider，支持多个版本以便轮换主密钥
+type LocalKeyProvider struct {
+	current string
+	keys    map[string]cipher.AEAD
+}
+
+// localKeyFile 本地主密钥文件格式，keys 的值为base64编码的32字节密钥
+type localKeyFile struct {
+	Current string            `json:"current"`
+	Keys    map[string]string `json:"keys"`
+}
+
+// NewLocalKeyProvider 创建本地主密钥提供者，current 为加密新数据密钥使用的版本
+func NewLocalKeyProvider(current string, keys map[string][]byte) (*LocalKeyProvider, error) {
+	if _, ok := keys[current]; !ok {
+		return nil, fmt.Errorf("%w: 缺少当前版本的主密钥 %s", ErrInvalidKey, current)
+	}
+
+	p := &LocalKeyProvider{current: current, keys: make(map[string]cipher.AEAD, len(keys))}
+	for version, key := range keys {
+		if len(key) != dataKeySize {
+			return nil, fmt.Errorf("%w: 主密钥 %s 长度必须为%d字节", ErrInvalidKey, version, dataKeySize)
+		}
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, err
+		}
+		p.keys[version] = aead
+	}
+	return p, nil
+}
+
+// LoadLocalKeyProvider 从JSON文件加载本地主密钥，格式为
+// {"current": "2", "keys": {"1": "<base64>", "2": "<base64>"}}
+func LoadLocalKeyProvider(path string) (*LocalKeyProvider, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取主密钥文件失败: %w", err)
+	}
+
+	var file localKeyFile
+	if err := json.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("解析主密钥文件失败: %w", err)
+	}
+
+	keys := make(map[string][]byte, len(file.Keys))
+	for version, encoded := range file.Keys {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("%w: 主密钥 %s 不是有效的base64", ErrInvalidKey, version)
+		}
+		keys[version] = key
+	}
+	return NewLocalKeyProvider(file.Current, keys)
+}
+
+// CurrentVersion 返回当前主密钥版本
+func (p *LocalKeyProvider) CurrentVersion() string {
+	return p.current
+}
+
+// WrapKey 使用当前主密钥加密数据密钥
+func (p *LocalKeyProvider) WrapKey(_ context.Context, dataKey []byte) ([]byte, string, error) {
+	wrapped, err := aeadSeal(p.keys[p.current], dataKey, []byte(p.current))
+	return wrapped, p.current, err
+}
+
+// UnwrapKey 使用指定版本的主密钥解密数据密钥
+func (p *LocalKeyProvider) UnwrapKey(_ context.Context, wrapped []byte, version string) ([]byte, error) {
+	aead, ok := p.keys[version]
+	if !ok {
+		return nil, fmt.Errorf("%w: 未知的主密钥版本 %s", ErrKeyEncryption, version)
+	}
+	return aeadOpen(aead, wrapped, []byte(version))
+}
+
+// sealSecret 使用新的数据密钥加密 plaintext，返回 <wrapped>.<ciphertext> 和主密钥版本
+// aad 绑定记录的位置，防止密文在记录之间被替换
+func sealSecret(ctx context.Context, provider KeyEncryptionProvider, plaintext, aad string) (string, string, error) {
+	dataKey := make([]byte, dataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", "", fmt.Errorf("%w: 生成数据密钥失败: %v", ErrKeyEncryption, err)
+	}
+
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return "", "", err
+	}
+	ciphertext, err := aeadSeal(aead, []byte(plaintext), []byte(aad))
+	if err != nil {
+		return "", "", err
+	}
+
+	wrapped, version, err := provider.WrapKey(ctx, dataKey)
+	if err != nil {
+		return "", "", encryptionError(err)
+	}
+	return encodeEnvelope(wrapped, ciphertext), version, nil
+}
+
+// openSecret 解密 sealSecret 生成的密文
+func openSecret(ctx context.Context, provider KeyEncryptionProvider, sealed, version, aad string) (string, error) {
+	wrapped, ciphertext, err := decodeEnvelope(sealed)
+	if err != nil {
+		return "", err
+	}
+
+	dataKey, err := provider.UnwrapKey(ctx, wrapped, version)
+	if err != nil {
+		return "", encryptionError(err)
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := aeadOpen(aead, ciphertext, []byte(aad))
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// rewrapSecret 使用当前主密钥重新加密数据密钥，密文本身不变
+func rewrapSecret(ctx context.Context, provider KeyEncryptionProvider, sealed, version string) (string, string, error) {
+	wrapped, ciphertext, err := decodeEnvelope(sealed)
+	if err != nil {
+		return "", "", err
+	}
+
+	dataKey, err := provider.UnwrapKey(ctx, wrapped, version)
+	if err != nil {
+		return "", "", encryptionError(err)
+	}
+	wrapped, version, err = provider.WrapKey(ctx, dataKey)
+	if err != nil {
+		return "", "", encryptionError(err)
+	}
+	return encodeEnvelope(wrapped, ciphertext), version, nil
+}
+
+// encryptionError 将提供者返回的错误包装为 ErrKeyEncryption
+func encryptionError(err error) error {
+	if errors.Is(err, ErrKeyEncryption) {
+		return err
+	}
+	return fmt.Errorf("%w: %v", ErrKeyEncryption, err)
+}
+
+func encodeEnvelope(wrapped, ciphertext []byte) string {
+	return base64.RawStdEncoding.EncodeToString(wrapped) + "." + base64.RawStdEncoding.EncodeToString(ciphertext)
+}
+
+func decodeEnvelope(sealed string) ([]byte, []byte, error) {
+	wrappedPart, ciphertextPart, ok := strings.Cut(sealed, ".")
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: 密文格式错误", ErrKeyEncryption)
+	}
+	wrapped, err := base64.RawStdEncoding.DecodeString(wrappedPart)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: 密文格式错误", ErrKeyEncryption)
+	}
+	ciphertext, err := base64.RawStdEncoding.DecodeString(ciphertextPart)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: 密文格式错误", ErrKeyEncryption)
+	}
+	return wrapped, ciphertext, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrKeyEncryption, err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrKeyEncryption, err)
+	}
+	return aead, nil
+}
+
+// aeadSeal 加密并在密文前附加随机nonce
+func aeadSeal(aead cipher.AEAD, plaintext, aad []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("%w: 生成nonce失败: %v", ErrKeyEncryption, err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+// aeadOpen 解密 aeadSeal 的输出
+func aeadOpen(aead cipher.AEAD, sealed, aad []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, fmt.Errorf("%w: 密文长度错误", ErrKeyEncryption)
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, aad)
+	if err != nil {
+		return nil, fmt.Errorf("%w: 解密失败", ErrKeyEncryption)
+	}
+	return plaintext, nil
+}
+
+// keyRewrapper 支持重新加密数据密钥的存储
+type keyRewrapper interface {
+	RewrapKeys(ctx context.Context) (int, error)
+}
+
+// RewrapKeys 使用当前主密钥重新加密存储中的全部数据密钥，返回更新的记录数
+// 轮换主密钥时，先在提供者中加入新版本并设为当前版本，执行本方法后再移除旧版本
+func (s *SignatureSDK) RewrapKeys(ctx context.Context) (int, error) {
+	store := s.store
+	if s.cache != nil {
+		store = s.cache.KeyStore
+	}
+
+	rewrapper, ok := store.(keyRewrapper)
+	if !ok {
+		return 0, fmt.Errorf("%w: 存储不支持加密", ErrKeyEncryption)
+	}
+	return rewrapper.RewrapKeys(ctx)
+}
@@ -0,0 +1,172 @@
+package go_signature_sdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestLocalKeyProvider 测试本地主密钥的信封加密和主密钥轮换
+func TestLocalKeyProvider(t *testing.T) {
+	ctx := context.Background()
+	key1 := bytes.Repeat([]byte{1}, 32)
+	key2 := bytes.Repeat([]byte{2}, 32)
+
+	p1, err := NewLocalKeyProvider("1", map[string][]byte{"1": key1})
+	if err != nil {
+		t.Fatalf("创建主密钥失败: %v", err)
+	}
+	sealed, version, err := sealSecret(ctx, p1, "test_secret", appKeyAAD("test_app"))
+	if err != nil {
+		t.Fatalf("加密失败: %v", err)
+	}
+	if version != "1" || strings.Contains(sealed, "test_secret") {
+		t.Fatalf("加密结果错误: %s %s", version, sealed)
+	}
+
+	plaintext, err := openSecret(ctx, p1, sealed, version, appKeyAAD("test_app"))
+	if err != nil || plaintext != "test_secret" {
+		t.Fatalf("解密失败: %q, %v", plaintext, err)
+	}
+	// 密文不能用于其他记录
+	if _, err := openSecret(ctx, p1, sealed, version, appKeyAAD("other_app")); !errors.Is(err, ErrKeyEncryption) {
+		t.Errorf("期望解密失败, 实际: %v", err)
+	}
+
+	// 轮换到版本2后重新加密数据密钥，移除版本1仍可解密
+	p2, _ := NewLocalKeyProvider("2", map[string][]byte{"1": key1, "2": key2})
+	rewrapped, version, err := rewrapSecret(ctx, p2, sealed, version)
+	if err != nil || version != "2" {
+		t.Fatalf("重新加密失败: %s, %v", version, err)
+	}
+	p3, _ := NewLocalKeyProvider("2", map[string][]byte{"2": key2})
+	if plaintext, err := openSecret(ctx, p3, rewrapped, version, appKeyAAD("test_app")); err != nil || plaintext != "test_secret" {
+		t.Errorf("重新加密后解密失败: %q, %v", plaintext, err)
+	}
+	if _, err := openSecret(ctx, p3, sealed, "1", appKeyAAD("test_app")); !errors.Is(err, ErrKeyEncryption) {
+		t.Errorf("期望未知版本错误, 实际: %v", err)
+	}
+
+	if _, err := NewLocalKeyProvider("1", map[string][]byte{"1": key1[:16]}); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("期望主密钥长度错误, 实际: %v", err)
+	}
+	if _, err := NewLocalKeyProvider("3", map[string][]byte{"1": key1}); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("期望缺少当前版本错误, 实际: %v", err)
+	}
+}
+
+// TestLoadLocalKeyProvider 测试从文件加载主密钥
+func TestLoadLocalKeyProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "master.json")
+	content := `{"current": "1", "keys": {"1": "` + base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32)) + `"}}`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("写入主密钥文件失败: %v", err)
+	}
+
+	p, err := LoadLocalKeyProvider(path)
+	if err != nil {
+		t.Fatalf("加载主密钥失败: %v", err)
+	}
+	if p.CurrentVersion() != "1" {
+		t.Errorf("当前版本错误: %s", p.CurrentVersion())
+	}
+}
+
+// TestSDKKeyEncryption 测试PostgreSQL中的密钥加密存储
+func TestSDKKeyEncryption(t *testing.T) {
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+	ctx := context.Background()
+
+	key1 := bytes.Repeat([]byte{1}, 32)
+	p1, _ := NewLocalKeyProvider("1", map[string][]byte{"1": key1})
+	sdk, err := New(&Config{DB: db, KeyEncryption: p1})
+	if err != nil {
+		t.Fatalf("创建SDK失败: %v", err)
+	}
+
+	appID := "test_app_encrypted"
+	if err := sdk.CreateAppKey(appID, "test_secret", []string{}, nil); err != nil {
+		t.Fatalf("创建测试应用失败: %v", err)
+	}
+	if err := sdk.AddSecret(ctx, appID, &AppSecret{Secret: "second_secret"}); err != nil {
+		t.Fatalf("添加密钥失败: %v", err)
+	}
+
+	assertStored := func(version string) {
+		t.Helper()
+		var secretKey, keyVersion string
+		err := db.QueryRow(`SELECT secret_key, key_version FROM app_keys WHERE app_id = $1`, appID).Scan(&secretKey, &keyVersion)
+		if err != nil {
+			t.Fatalf("查询应用失败: %v", err)
+		}
+		if keyVersion != version || strings.Contains(secretKey, "test_secret") {
+			t.Errorf("密钥未加密存储: %s %s", keyVersion, secretKey)
+		}
+		rows, err := db.Query(`SELECT secret, key_version FROM app_secrets WHERE app_id = $1`, appID)
+		if err != nil {
+			t.Fatalf("查询密钥失败: %v", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var secret, keyVersion string
+			rows.Scan(&secret, &keyVersion)
+			if keyVersion != version || strings.HasSuffix(secret, "_secret") {
+				t.Errorf("密钥未加密存储: %s %s", keyVersion, secret)
+			}
+		}
+	}
+	assertStored("1")
+
+	appKey, err := sdk.GetAppKey(appID)
+	if err != nil || appKey.SecretKey != "test_secret" || len(appKey.Secrets) != 2 {
+		t.Fatalf("解密应用失败: %+v, %v", appKey, err)
+	}
+
+	// 轮换主密钥
+	p2, _ := NewLocalKeyProvider("2", map[string][]byte{"1": key1, "2": bytes.Repeat([]byte{2}, 32)})
+	sdk2 := NewSignatureSDK(&Config{DB: db, KeyEncryption: p2, SkipMigrations: true})
+
+	// 重新加密期间应用行被其他事务锁定，需要等待而不是跳过
+	lock, err := db.Begin()
+	if err != nil {
+		t.Fatalf("开始事务失败: %v", err)
+	}
+	if _, err := lock.Exec(`SELECT 1 FROM app_keys WHERE app_id = $1 FOR UPDATE`, appID); err != nil {
+		t.Fatalf("锁定应用失败: %v", err)
+	}
+	type rewrapResult struct {
+		n   int
+		err error
+	}
+	done := make(chan rewrapResult, 1)
+	go func() {
+		n, err := sdk2.RewrapKeys(ctx)
+		done <- rewrapResult{n, err}
+	}()
+	time.Sleep(100 * time.Millisecond)
+	lock.Commit()
+
+	if r := <-done; r.err != nil || r.n != 3 {
+		t.Fatalf("重新加密失败: %d, %v", r.n, r.err)
+	}
+	assertStored("2")
+	if n, err := sdk2.RewrapKeys(ctx); err != nil || n != 0 {
+		t.Errorf("重复执行不应更新记录: %d, %v", n, err)
+	}
+	if appKey, err := sdk2.GetAppKey(appID); err != nil || appKey.SecretKey != "test_secret" {
+		t.Errorf("重新加密后解密失败: %+v, %v", appKey, err)
+	}
+
+	// 未配置主密钥时无法读取加密的记录
+	plain := NewSignatureSDK(&Config{DB: db, SkipMigrations: true})
+	if _, err := plain.GetAppKey(appID); !errors.Is(err, ErrKeyEncryption) {
+		t.Errorf("期望加解密错误, 实际: %v", err)
+	}
+}
@@ -1,12 +1,56 @@
 package go_signature_sdk
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 // 错误定义
 var (
 	ErrAppNotFound    = errors.New("应用不存在")
+	ErrAppExists      = errors.New("应用ID已存在")
 	ErrAppDisabled    = errors.New("应用已禁用")
 	ErrIPNotAllowed   = errors.New("IP不在白名单中")
 	ErrInvalidSign    = errors.New("签名验证失败")
 	ErrExpiredRequest = errors.New("请求已过期")
+
+	ErrMissingTimestamp = errors.New("缺少时间戳")
+	ErrMissingNonce     = errors.New("缺少nonce")
+	ErrReplayedRequest  = errors.New("重复的请求")
+	ErrInvalidRequest   = errors.New("请求参数无效")
+
+	ErrUnsupportedSignType = errors.New("不支持的签名算法")
+	ErrSignTypeNotAllowed  = errors.New("签名算法不在应用允许范围内")
+	ErrInvalidKey          = errors.New("无效的密钥")
+	ErrPrivateKeyRequired  = errors.New("非对称签名需要私钥")
+
+	ErrSecretNotFound  = errors.New("密钥不存在")
+	ErrSecretExists    = errors.New("密钥ID已存在")
+	ErrPrimarySecret   = errors.New("不能删除主密钥")
+	ErrKeyEncryption   = errors.New("密钥加解密失败")
+	ErrRowTampered     = errors.New("应用数据完整性校验失败")
+	ErrVersionConflict = errors.New("应用已被修改，版本不一致")
+
+	ErrInvalidCredential = errors.New("凭证格式或校验码错误")
+	ErrInvalidTransition = errors.New("不允许的状态变更")
+	ErrRestoreExpired    = errors.New("已超过恢复期限")
+	ErrInvalidAppID      = errors.New("应用ID不符合规则")
+	ErrWeakSecret        = errors.New("密钥强度不足")
 )
+
+// 应用状态错误，均可用 errors.Is 判断为 ErrAppDisabled
+var (
+	ErrAppSuspended = fmt.Errorf("%w: 已暂停", ErrAppDisabled)
+	ErrAppPending   = fmt.Errorf("%w: 待审核", ErrAppDisabled)
+	ErrAppRevoked   = fmt.Errorf("%w: 已吊销", ErrAppDisabled)
+	ErrAppDeleted   = fmt.Errorf("%w: 已删除", ErrAppDisabled)
+)
+
+// ErrMissingSign 请求未携带签名，可用 errors.Is 判断为 ErrInvalidSign
+var ErrMissingSign = fmt.Errorf("%w: 缺少签名", ErrInvalidSign)
+
+// ErrEmptyWhitelist 删除后IP白名单为空（即不限制IP），可用 errors.Is 判断为 ErrInvalidRequest
+var ErrEmptyWhitelist = fmt.Errorf("%w: 删除后IP白名单为空，清空白名单需使用 ClearWhitelist", ErrInvalidRequest)
+
+// ErrAppPurged 应用ID已被清除，不能重新创建，可用 errors.Is 判断为 ErrAppExists
+var ErrAppPurged = fmt.Errorf("%w: 已清除，不能重新使用", ErrAppExists)
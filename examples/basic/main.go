@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	_ "github.com/lib/pq"
@@ -19,38 +20,42 @@ func main() {
 	// 创建SDK实例
 	sdk := go_signature_sdk.NewSignatureSDK(&go_signature_sdk.Config{DB: db})
 
-	// 创建应用密钥
-	err = sdk.CreateAppKey("test_app", "my_secret_key", []string{"127.0.0.1"}, map[string]interface{}{"description": "测试应用"})
+	// 签发应用凭证，密钥只返回这一次
+	creds, err := sdk.IssueCredentials(context.Background(), go_signature_sdk.IssueOptions{
+		Environment: go_signature_sdk.CredentialTest,
+		IPsWhite:    []string{"127.0.0.1"},
+		Attributes:  map[string]interface{}{"description": "测试应用"},
+	})
 	if err != nil {
-		log.Println("创建应用密钥失败:", err)
+		log.Fatal("签发凭证失败:", err)
 	}
+	fmt.Println("签发的凭证:", creds)
 
 	// 生成签名
-	params := &go_signature_sdk.SignParams{
-		AppID: "test_app",
-		Data: map[string]interface{}{
-			"user_id": "12345",
-			"action":  "login",
-		},
+	ctx := context.Background()
+	data := map[string]interface{}{
+		"user_id": "12345",
+		"action":  "login",
 	}
-
-	if err, sign := sdk.GenerateSign(params); err != nil {
-		log.Fatal("签名生成失败:", err, sign)
+	sign, err := sdk.Sign(ctx, &go_signature_sdk.SignParams{AppID: creds.AppID, Data: data})
+	if err != nil {
+		log.Fatal("签名生成失败:", err)
 	}
 
-	fmt.Printf("生成的签名: %s\n", params.Data["sign"])
+	fmt.Printf("生成的签名: %s\n", sign)
 
 	// 验证签名
 	verifyParams := &go_signature_sdk.VerifyParams{
-		AppID:    params.AppID,
-		Data:     params.Data,
+		AppID:    creds.AppID,
+		Sign:     sign,
+		Data:     data,
 		ClientIP: "127.0.0.1",
 	}
 
-	err = sdk.VerifySign(verifyParams)
+	result, err := sdk.Verify(ctx, verifyParams)
 	if err != nil {
 		log.Fatal("签名验证失败:", err)
 	}
 
-	fmt.Println("签名验证成功!")
+	fmt.Println("签名验证成功! 算法:", result.SignType)
 }
@@ -0,0 +1,106 @@
+package go_signature_sdk
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// TamperedRow 完整性校验失败的应用
+type TamperedRow struct {
+	AppID  string `json:"app_id"`
+	Reason string `json:"reason"`
+}
+
+// rowMACFields 参与 row_mac 计算的字段，字段顺序固定
+type rowMACFields struct {
+	AppID            string          `json:"app_id"`
+	SecretKey        string          `json:"secret_key"`
+	IPsWhite         []string        `json:"ips_white"`
+	Status           int             `json:"status"`
+	SignType         SignType        `json:"sign_type"`
+	AllowedSignTypes []SignType      `json:"allowed_sign_types"`
+	PublicKey        string          `json:"public_key"`
+	Secrets          []rowMACSecrets `json:"secrets"`
+}
+
+type rowMACSecrets struct {
+	KeyID     string `json:"key_id"`
+	Secret    string `json:"secret"`
+	Primary   bool   `json:"primary"`
+	NotBefore int64  `json:"not_before"`
+	NotAfter  int64  `json:"not_after"`
+}
+
+// computeRowMAC 计算应用安全相关字段的 HMAC-SHA256
+// 覆盖密钥、IP白名单、状态、签名算法、公钥以及 app_secrets 中的全部密钥，不包括 Attributes
+func computeRowMAC(key []byte, appKey *AppKey) string {
+	fields := rowMACFields{
+		AppID:            appKey.AppID,
+		SecretKey:        appKey.SecretKey,
+		IPsWhite:         appKey.IPsWhite,
+		Status:           int(appKey.Status),
+		SignType:         appKey.SignType,
+		AllowedSignTypes: appKey.AllowedSignTypes,
+		PublicKey:        appKey.PublicKey,
+		Secrets:          make([]rowMACSecrets, 0, len(appKey.Secrets)),
+	}
+	if fields.IPsWhite == nil {
+		fields.IPsWhite = []string{}
+	}
+	if fields.AllowedSignTypes == nil {
+		fields.AllowedSignTypes = []SignType{}
+	}
+	for _, s := range appKey.Secrets {
+		fields.Secrets = append(fields.Secrets, rowMACSecrets{
+			KeyID:     s.KeyID,
+			Secret:    s.Secret,
+			Primary:   s.Primary,
+			NotBefore: s.NotBefore,
+			NotAfter:  s.NotAfter,
+		})
+	}
+	sort.Slice(fields.Secrets, func(i, j int) bool { return fields.Secrets[i].KeyID < fields.Secrets[j].KeyID })
+
+	content, _ := json.Marshal(fields)
+	mac := hmac.New(sha256.New, key)
+	mac.Write(content)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// checkRowMAC 校验应用的 row_mac，key 为空时不校验
+func checkRowMAC(key []byte, appKey *AppKey, rowMAC string) error {
+	if len(key) == 0 {
+		return nil
+	}
+	if rowMAC == "" {
+		return fmt.Errorf("%w: %s 缺少row_mac", ErrRowTampered, appKey.AppID)
+	}
+	if !hmac.Equal([]byte(rowMAC), []byte(computeRowMAC(key, appKey))) {
+		return fmt.Errorf("%w: %s", ErrRowTampered, appKey.AppID)
+	}
+	return nil
+}
+
+// rowVerifier 支持完整性校验的存储
+type rowVerifier interface {
+	VerifyRows(ctx context.Context) ([]TamperedRow, error)
+}
+
+// VerifyAppKeys 扫描存储中的全部应用，返回完整性校验失败的应用
+func (s *SignatureSDK) VerifyAppKeys(ctx context.Context) ([]TamperedRow, error) {
+	store := s.store
+	if s.cache != nil {
+		store = s.cache.KeyStore
+	}
+
+	verifier, ok := store.(rowVerifier)
+	if !ok {
+		return nil, fmt.Errorf("存储不支持完整性校验")
+	}
+	return verifier.VerifyRows(ctx)
+}
@@ -0,0 +1,172 @@
+package go_signature_sdk
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// TestRowMAC 测试 row_mac 覆盖的字段
+func TestRowMAC(t *testing.T) {
+	key := []byte("test_row_mac_key")
+	appKey := &AppKey{
+		AppID:     "test_app",
+		SecretKey: "test_secret",
+		IPsWhite:  []string{"127.0.0.1"},
+		Status:    1,
+		SignType:  SignTypeMD5,
+		Secrets: []AppSecret{
+			{KeyID: "k1", Secret: "test_secret", Primary: true},
+			{KeyID: "k2", Secret: "old_secret", NotAfter: 1700000000},
+		},
+	}
+	mac := computeRowMAC(key, appKey)
+	if err := checkRowMAC(key, appKey, mac); err != nil {
+		t.Fatalf("row_mac校验失败: %v", err)
+	}
+
+	// Attributes 和密钥顺序不影响 row_mac
+	same := cloneAppKey(appKey)
+	same.Attributes = map[string]interface{}{"description": "changed"}
+	same.Secrets[0], same.Secrets[1] = same.Secrets[1], same.Secrets[0]
+	if err := checkRowMAC(key, same, mac); err != nil {
+		t.Errorf("无关字段影响了row_mac: %v", err)
+	}
+
+	changes := map[string]func(a *AppKey){
+		"secret":    func(a *AppKey) { a.SecretKey = "forged" },
+		"ips_white": func(a *AppKey) { a.IPsWhite = []string{"0.0.0.0/0"} },
+		"status":    func(a *AppKey) { a.Status = 0 },
+		"sign_type": func(a *AppKey) { a.SignType = SignTypeHMACSHA256 },
+		"allowed":   func(a *AppKey) { a.AllowedSignTypes = []SignType{SignTypeMD5} },
+		"not_after": func(a *AppKey) { a.Secrets[1].NotAfter = 0 },
+		"secrets":   func(a *AppKey) { a.Secrets = append(a.Secrets, AppSecret{KeyID: "k3", Secret: "forged"}) },
+	}
+	for name, change := range changes {
+		t.Run(name, func(t *testing.T) {
+			tampered := cloneAppKey(appKey)
+			change(tampered)
+			if err := checkRowMAC(key, tampered, mac); !errors.Is(err, ErrRowTampered) {
+				t.Errorf("期望完整性校验失败, 实际: %v", err)
+			}
+		})
+	}
+
+	if err := checkRowMAC(key, appKey, ""); !errors.Is(err, ErrRowTampered) {
+		t.Errorf("期望缺少row_mac错误, 实际: %v", err)
+	}
+	if err := checkRowMAC(nil, appKey, ""); err != nil {
+		t.Errorf("未配置密钥时不应校验: %v", err)
+	}
+}
+
+// TestSDKRowMAC 测试PostgreSQL中被直接修改的应用
+func TestSDKRowMAC(t *testing.T) {
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+	ctx := context.Background()
+
+	sdk, err := New(&Config{DB: db, RowMACKey: []byte("test_row_mac_key")})
+	if err != nil {
+		t.Fatalf("创建SDK失败: %v", err)
+	}
+
+	for _, appID := range []string{"test_app_mac1", "test_app_mac2"} {
+		if err := sdk.CreateAppKey(appID, "test_secret", []string{"127.0.0.1"}, nil); err != nil {
+			t.Fatalf("创建测试应用失败: %v", err)
+		}
+	}
+	if _, err := sdk.RotateSecret(ctx, "test_app_mac1", 0); err != nil {
+		t.Fatalf("轮换密钥失败: %v", err)
+	}
+	if err := sdk.SetSignTypes("test_app_mac1", SignTypeHMACSHA256, nil); err != nil {
+		t.Fatalf("设置签名算法失败: %v", err)
+	}
+	if _, err := sdk.SweepSecrets(ctx); err != nil {
+		t.Fatalf("清理过期密钥失败: %v", err)
+	}
+	if _, err := sdk.GetAppKey("test_app_mac1"); err != nil {
+		t.Fatalf("通过SDK修改后校验失败: %v", err)
+	}
+
+	// 绕过SDK修改白名单
+	if _, err := db.Exec(`UPDATE app_keys SET ips_white = '[]' WHERE app_id = 'test_app_mac2'`); err != nil {
+		t.Fatalf("修改应用失败: %v", err)
+	}
+	if _, err := sdk.GetAppKey("test_app_mac2"); !errors.Is(err, ErrRowTampered) {
+		t.Errorf("期望完整性校验失败, 实际: %v", err)
+	}
+
+	tampered, err := sdk.VerifyAppKeys(ctx)
+	if err != nil {
+		t.Fatalf("校验全部应用失败: %v", err)
+	}
+	if len(tampered) != 1 || tampered[0].AppID != "test_app_mac2" {
+		t.Errorf("校验结果错误: %+v", tampered)
+	}
+
+	// 返回密钥的列表跳过被篡改的应用并单独列出，其余应用正常返回
+	page, err := sdk.ListAppKeys(ctx, AppKeyFilter{IncludeSecrets: true})
+	if err != nil {
+		t.Fatalf("查询应用失败: %v", err)
+	}
+	if len(page.AppKeys) != 1 || page.AppKeys[0].AppID != "test_app_mac1" || len(page.AppKeys[0].Secrets) == 0 {
+		t.Errorf("列表结果错误: %+v", page.AppKeys)
+	}
+	if len(page.Tampered) != 1 || page.Tampered[0].AppID != "test_app_mac2" {
+		t.Errorf("列表校验结果错误: %+v", page.Tampered)
+	}
+
+	// 不返回密钥的列表不解密也不校验
+	page, err = sdk.ListAppKeys(ctx, AppKeyFilter{})
+	if err != nil {
+		t.Fatalf("查询应用失败: %v", err)
+	}
+	if len(page.AppKeys) != 2 || page.Tampered != nil {
+		t.Errorf("列表结果错误: %+v", page)
+	}
+}
+
+// TestSDKRowMACConcurrentRotation 测试密钥轮换与查询并发时不会误判为篡改
+func TestSDKRowMACConcurrentRotation(t *testing.T) {
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+	ctx := context.Background()
+
+	sdk, err := New(&Config{DB: db, RowMACKey: []byte("test_row_mac_key")})
+	if err != nil {
+		t.Fatalf("创建SDK失败: %v", err)
+	}
+	if err := sdk.CreateAppKey("test_app_mac", "test_secret", nil, nil); err != nil {
+		t.Fatalf("创建测试应用失败: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		for i := 0; i < 20; i++ {
+			if _, err := sdk.RotateSecret(ctx, "test_app_mac", 0); err != nil {
+				done <- err
+				return
+			}
+			if _, err := sdk.SweepSecrets(ctx); err != nil {
+				done <- err
+				return
+			}
+		}
+		done <- nil
+	}()
+
+	for {
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatalf("轮换密钥失败: %v", err)
+			}
+			return
+		default:
+		}
+		if _, err := sdk.GetAppKey("test_app_mac"); err != nil {
+			t.Fatalf("轮换期间查询应用失败: %v", err)
+		}
+	}
+}
@@ -14,9 +14,17 @@ func (s *SignatureSDK) verifyIPWhitelist(clientIP string, whitelist []string) er
 
 	clientIPAddr := net.ParseIP(clientIP)
 	if clientIPAddr == nil {
-		return fmt.Errorf("无效的客户端IP: %s", clientIP)
+		return fmt.Errorf("%w: 无效的客户端IP %s", ErrIPNotAllowed, clientIP)
 	}
 
+	if whitelistContains(whitelist, clientIP, clientIPAddr) {
+		return nil
+	}
+	return ErrIPNotAllowed
+}
+
+// whitelistContains 白名单中是否有与IP相同的条目或包含IP的CIDR
+func whitelistContains(whitelist []string, ip string, addr net.IP) bool {
 	for _, whiteIP := range whitelist {
 		// 支持单个IP和CIDR格式
 		if strings.Contains(whiteIP, "/") {
@@ -25,16 +33,15 @@ func (s *SignatureSDK) verifyIPWhitelist(clientIP string, whitelist []string) er
 			if err != nil {
 				continue
 			}
-			if ipNet.Contains(clientIPAddr) {
-				return nil
+			if ipNet.Contains(addr) {
+				return true
 			}
 		} else {
 			// 单个IP
-			if whiteIP == clientIP {
-				return nil
+			if whiteIP == ip {
+				return true
 			}
 		}
 	}
-
-	return ErrIPNotAllowed
+	return false
 }
@@ -0,0 +1,172 @@
+package go_signature_sdk
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// KeyStore 应用密钥存储
+type KeyStore interface {
+	// GetAppKey 获取应用，不存在时返回 ErrAppNotFound
+	GetAppKey(ctx context.Context, appID string) (*AppKey, error)
+	// CreateAppKey 创建应用并回填 ID，应用已存在时返回 ErrAppExists，应用ID已被清除时返回 ErrAppPurged
+	CreateAppKey(ctx context.Context, appKey *AppKey) error
+	// UpdateAppKey 更新应用的密钥、IP白名单、状态和Attributes，不存在时返回 ErrAppNotFound
+	// 状态改变时记录 appKey.StatusReason 和 ctx 中的操作人
+	UpdateAppKey(ctx context.Context, appKey *AppKey) error
+	// ListAppKeys 按条件分页查询应用，IncludeSecrets 为false时不返回密钥
+	ListAppKeys(ctx context.Context, filter *AppKeyFilter) (*AppKeyPage, error)
+	// PatchAppKey 修改 patch 中非空的字段，返回新的版本号；patch.Version 与当前版本不一致时返回 ErrVersionConflict
+	PatchAppKey(ctx context.Context, appID string, patch *AppKeyPatch) (int64, error)
+	// AddWhitelistIPs 原子地向IP白名单追加不存在的条目
+	AddWhitelistIPs(ctx context.Context, appID string, ips []string) error
+	// RemoveWhitelistIPs 原子地从IP白名单删除条目，删除后白名单为空时返回 ErrEmptyWhitelist 且不做修改
+	RemoveWhitelistIPs(ctx context.Context, appID string, ips []string) error
+	// MergeAttributes 原子地合并Attributes，值为 nil 的键会被删除
+	MergeAttributes(ctx context.Context, appID string, attributes map[string]interface{}) error
+	// SetStatus 修改应用状态并记录原因和操作人，不允许的变更返回 ErrInvalidTransition
+	SetStatus(ctx context.Context, appID string, change *StatusChange) error
+	// RestoreAppKey 将 deletedAfter 之后删除的应用恢复为 change.Status，超过期限返回 ErrRestoreExpired
+	RestoreAppKey(ctx context.Context, appID string, deletedAfter int64, change *StatusChange) error
+	// PurgeDeleted 清除在 deletedBefore 之前删除的应用并记录应用ID，返回被清除的应用ID
+	PurgeDeleted(ctx context.Context, deletedBefore, now int64) ([]string, error)
+	// SetSignTypes 设置应用的签名算法
+	SetSignTypes(ctx context.Context, appID string, signType SignType, allowed []SignType) error
+	// SetPublicKey 设置应用验签使用的PEM公钥
+	SetPublicKey(ctx context.Context, appID, publicKey string) error
+	// AddSecret 添加密钥，Primary 为true时同时更新主密钥；密钥ID重复时返回 ErrSecretExists
+	AddSecret(ctx context.Context, appID string, secret *AppSecret) error
+	// RemoveSecret 删除密钥，不存在时返回 ErrSecretNotFound，删除主密钥时返回 ErrPrimarySecret
+	RemoveSecret(ctx context.Context, appID, keyID string) error
+	// SetPrimarySecret 设置主密钥，不存在时返回 ErrSecretNotFound
+	SetPrimarySecret(ctx context.Context, appID, keyID string) error
+	// RotateSecret 将 secret 设为主密钥，原主密钥最晚在 notAfter 失效，返回原主密钥ID
+	RotateSecret(ctx context.Context, appID string, secret *AppSecret, notAfter int64) (string, error)
+	// DeleteExpiredSecrets 删除全部应用中在 now 时已失效的非主密钥
+	DeleteExpiredSecrets(ctx context.Context, now int64) ([]RetiredSecret, error)
+	// History 返回应用的全部审计记录，按ID升序；以上写操作都需要在同一事务中写入审计记录
+	History(ctx context.Context, appID string) ([]AppKeyEvent, error)
+	// GetAppKeyAt 返回应用在 at 时刻（含）的脱敏快照，没有记录时返回 ErrAppNotFound
+	GetAppKeyAt(ctx context.Context, appID string, at int64) (*AppKey, error)
+}
+
+// GetAppKey 根据app_id获取应用密钥信息
+func (s *SignatureSDK) GetAppKey(appID string) (*AppKey, error) {
+	return s.GetAppKeyContext(context.Background(), appID)
+}
+
+// GetAppKeyContext 与 GetAppKey 相同，ctx 取消或超时后查询立即返回
+func (s *SignatureSDK) GetAppKeyContext(ctx context.Context, appID string) (*AppKey, error) {
+	return s.store.GetAppKey(ctx, appID)
+}
+
+// CreateAppKey 创建应用密钥，应用ID或密钥不符合 CredentialPolicy 时返回 *CredentialPolicyError
+func (s *SignatureSDK) CreateAppKey(appID, secretKey string, ipsWhite []string, attributes map[string]interface{}) error {
+	return s.CreateAppKeyContext(context.Background(), appID, secretKey, ipsWhite, attributes)
+}
+
+// CreateAppKeyContext 与 CreateAppKey 相同，ctx 取消或超时后事务回滚
+func (s *SignatureSDK) CreateAppKeyContext(ctx context.Context, appID, secretKey string, ipsWhite []string, attributes map[string]interface{}) error {
+	if err := s.policy.ValidateAppID(appID); err != nil {
+		return err
+	}
+	if err := s.policy.ValidateSecret(secretKey); err != nil {
+		return err
+	}
+	if ipsWhite == nil {
+		ipsWhite = []string{}
+	}
+	if attributes == nil {
+		attributes = map[string]interface{}{}
+	}
+
+	appKey := &AppKey{
+		AppID:      appID,
+		SecretKey:  secretKey,
+		IPsWhite:   ipsWhite,
+		Status:     StatusActive,
+		CreateAt:   time.Now().Unix(),
+		Attributes: attributes,
+		SignType:   SignTypeMD5,
+	}
+	return s.store.CreateAppKey(ctx, appKey)
+}
+
+// UpdateAppKey 更新应用密钥，status 需符合状态变更规则，密钥不符合 CredentialPolicy 时返回 *CredentialPolicyError
+func (s *SignatureSDK) UpdateAppKey(appID, secretKey string, ipsWhite []string, status int, attributes map[string]interface{}) error {
+	return s.UpdateAppKeyContext(context.Background(), appID, secretKey, ipsWhite, status, attributes)
+}
+
+// UpdateAppKeyContext 与 UpdateAppKey 相同，ctx 取消或超时后事务回滚
+// 状态改变时记录 ctx 中的操作人（见 WithActor），需要记录原因时使用 SetStatus 或 PatchAppKey
+func (s *SignatureSDK) UpdateAppKeyContext(ctx context.Context, appID, secretKey string, ipsWhite []string, status int, attributes map[string]interface{}) error {
+	if err := s.policy.ValidateSecret(secretKey); err != nil {
+		return err
+	}
+	if ipsWhite == nil {
+		ipsWhite = []string{}
+	}
+	if attributes == nil {
+		attributes = map[string]interface{}{}
+	}
+
+	appKey := &AppKey{
+		AppID:      appID,
+		SecretKey:  secretKey,
+		IPsWhite:   ipsWhite,
+		Status:     AppStatus(status),
+		Attributes: attributes,
+	}
+	return s.store.UpdateAppKey(ctx, appKey)
+}
+
+// SetSignTypes 设置应用的签名算法
+// signType 用于生成签名，allowed 为迁移期间验签额外接受的算法；
+// 确认旧算法不再有请求后（见 SignTypeUsage），传入空的 allowed 即可只接受 signType
+func (s *SignatureSDK) SetSignTypes(appID string, signType SignType, allowed []SignType) error {
+	return s.SetSignTypesContext(context.Background(), appID, signType, allowed)
+}
+
+// SetSignTypesContext 与 SetSignTypes 相同，ctx 取消或超时后事务回滚
+func (s *SignatureSDK) SetSignTypesContext(ctx context.Context, appID string, signType SignType, allowed []SignType) error {
+	for _, t := range append([]SignType{signType}, allowed...) {
+		if _, err := GetSigner(t); err != nil {
+			return err
+		}
+	}
+	return s.store.SetSignTypes(ctx, appID, signType, allowed)
+}
+
+// SetPublicKey 设置应用验签使用的PEM公钥，公钥需与应用的某个非对称算法匹配
+func (s *SignatureSDK) SetPublicKey(appID, publicKey string) error {
+	return s.SetPublicKeyContext(context.Background(), appID, publicKey)
+}
+
+// SetPublicKeyContext 与 SetPublicKey 相同，ctx 取消或超时后事务回滚
+func (s *SignatureSDK) SetPublicKeyContext(ctx context.Context, appID, publicKey string) error {
+	appKey, err := s.GetAppKeyContext(ctx, appID)
+	if err != nil {
+		return err
+	}
+
+	var matched bool
+	var lastErr error
+	for _, t := range append([]SignType{appKey.SignType}, appKey.AllowedSignTypes...) {
+		if !IsAsymmetric(t) {
+			continue
+		}
+		if lastErr = ValidatePublicKey(t, publicKey); lastErr == nil {
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		if lastErr == nil {
+			lastErr = fmt.Errorf("%w: 应用未配置非对称签名算法", ErrInvalidKey)
+		}
+		return lastErr
+	}
+
+	return s.store.SetPublicKey(ctx, appID, publicKey)
+}
@@ -0,0 +1,218 @@
+package go_signature_sdk
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+)
+
+// FileKeyStore 基于JSON文件的应用密钥存储，每次变更都会完整写回文件
+// 审计记录只追加到 path+".events.jsonl"，不随应用一起重写
+// 文件包含明文密钥，权限为0600；不支持多进程同时写入
+type FileKeyStore struct {
+	*MemoryKeyStore
+	path        string
+	savedEvents int   // 已写入审计记录文件的记录数
+	eventsSize  int64 // 审计记录文件中已提交部分的字节数，之后的内容来自写入失败的变更
+}
+
+// fileKeyStoreData JSON文件格式
+type fileKeyStoreData struct {
+	Apps       []*AppKey   `json:"apps"`
+	Tombstones []tombstone `json:"tombstones,omitempty"`
+	// EventCount 审计记录文件中属于已提交变更的记录数
+	EventCount int `json:"event_count,omitempty"`
+	// Events 早期版本写在JSON文件中的审计记录，首次写入时迁移到审计记录文件
+	Events []AppKeyEvent `json:"events,omitempty"`
+}
+
+// NewFileKeyStore 创建JSON文件应用密钥存储，文件不存在时在首次写入时创建
+func NewFileKeyStore(path string) (*FileKeyStore, error) {
+	f := &FileKeyStore{MemoryKeyStore: NewMemoryKeyStore(), path: path}
+
+	content, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("读取密钥文件失败: %w", err)
+	}
+	if len(content) > 0 {
+		var data fileKeyStoreData
+		if err := json.Unmarshal(content, &data); err != nil {
+			return nil, fmt.Errorf("解析密钥文件失败: %w", err)
+		}
+		for _, appKey := range data.Apps {
+			f.apps[appKey.AppID] = appKey
+			if appKey.ID > f.nextID {
+				f.nextID = appKey.ID
+			}
+		}
+		for _, t := range data.Tombstones {
+			f.tombstones[t.AppID] = t
+		}
+		if data.Events != nil {
+			f.events = data.Events
+		} else if err := f.loadEvents(data.EventCount); err != nil {
+			return nil, err
+		}
+	}
+
+	f.onChange = f.save
+	return f, nil
+}
+
+// eventsPath 审计记录文件的路径
+func (f *FileKeyStore) eventsPath() string {
+	return f.path + ".events.jsonl"
+}
+
+// loadEvents 读取审计记录文件的前 count 行，之后的行来自写入失败的变更，忽略
+func (f *FileKeyStore) loadEvents(count int) error {
+	if count == 0 {
+		return nil
+	}
+	file, err := os.Open(f.eventsPath())
+	if err != nil {
+		return fmt.Errorf("读取审计记录文件失败: %w", err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	events := make([]AppKeyEvent, 0, count)
+	var size int64
+	for len(events) < count {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			return fmt.Errorf("审计记录文件不完整: 期望 %d 条, 实际 %d 条", count, len(events))
+		}
+		if err != nil {
+			return fmt.Errorf("读取审计记录文件失败: %w", err)
+		}
+		var event AppKeyEvent
+		if err := json.Unmarshal(line, &event); err != nil {
+			return fmt.Errorf("解析审计记录文件失败: %w", err)
+		}
+		events = append(events, event)
+		size += int64(len(line))
+	}
+	f.events = events
+	f.savedEvents = count
+	f.eventsSize = size
+	return nil
+}
+
+// appendEvents 截掉审计记录文件中未提交的部分，追加新的记录并落盘，返回追加后的文件大小
+func (f *FileKeyStore) appendEvents() (int64, error) {
+	if len(f.events) == f.savedEvents {
+		return f.eventsSize, nil
+	}
+	var buf bytes.Buffer
+	for _, event := range f.events[f.savedEvents:] {
+		line, err := json.Marshal(event)
+		if err != nil {
+			return 0, fmt.Errorf("序列化审计记录失败: %w", err)
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	file, err := os.OpenFile(f.eventsPath(), os.O_WRONLY|os.O_CREATE, 0600)
+	if err != nil {
+		return 0, fmt.Errorf("写入审计记录文件失败: %w", err)
+	}
+	if err := file.Truncate(f.eventsSize); err != nil {
+		file.Close()
+		return 0, fmt.Errorf("写入审计记录文件失败: %w", err)
+	}
+	if _, err := file.WriteAt(buf.Bytes(), f.eventsSize); err != nil {
+		file.Close()
+		return 0, fmt.Errorf("写入审计记录文件失败: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return 0, fmt.Errorf("写入审计记录文件失败: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return 0, fmt.Errorf("写入审计记录文件失败: %w", err)
+	}
+	if f.eventsSize == 0 {
+		// 文件可能是新建的
+		if err := syncDir(filepath.Dir(f.path)); err != nil {
+			return 0, fmt.Errorf("写入审计记录文件失败: %w", err)
+		}
+	}
+	return f.eventsSize + int64(buf.Len()), nil
+}
+
+// save 先追加审计记录，再将全部应用写入临时文件并落盘后替换原文件，再同步目录使替换在断电后仍然有效，调用方需持有写锁
+// JSON文件记录已提交的审计记录数，替换失败时已追加的记录在下次写入时被截掉
+func (f *FileKeyStore) save() error {
+	eventsSize, err := f.appendEvents()
+	if err != nil {
+		return err
+	}
+
+	data := fileKeyStoreData{Apps: make([]*AppKey, 0, len(f.apps))}
+	for _, appKey := range f.apps {
+		data.Apps = append(data.Apps, appKey)
+	}
+	sort.Slice(data.Apps, func(i, j int) bool { return data.Apps[i].ID < data.Apps[j].ID })
+	for _, t := range f.tombstones {
+		data.Tombstones = append(data.Tombstones, t)
+	}
+	sort.Slice(data.Tombstones, func(i, j int) bool { return data.Tombstones[i].AppID < data.Tombstones[j].AppID })
+	data.EventCount = len(f.events)
+
+	content, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化密钥文件失败: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("写入密钥文件失败: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return fmt.Errorf("写入密钥文件失败: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("写入密钥文件失败: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("写入密钥文件失败: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		return fmt.Errorf("写入密钥文件失败: %w", err)
+	}
+	if err := syncDir(filepath.Dir(f.path)); err != nil {
+		return fmt.Errorf("写入密钥文件失败: %w", err)
+	}
+	f.savedEvents = len(f.events)
+	f.eventsSize = eventsSize
+	return nil
+}
+
+// syncDir 将目录项（如 rename 的结果）写入磁盘，Windows 不支持同步目录，跳过
+func syncDir(dir string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	if err := d.Sync(); err != nil {
+		d.Close()
+		return err
+	}
+	return d.Close()
+}
@@ -0,0 +1,522 @@
+package go_signature_sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryKeyStore 进程内应用密钥存储，适用于测试和无法访问数据库的服务
+type MemoryKeyStore struct {
+	mu         sync.RWMutex
+	apps       map[string]*AppKey
+	tombstones map[string]tombstone
+	events     []AppKeyEvent // 审计记录，按ID升序
+	nextID     int
+
+	// onChange 数据变更后在持有写锁时调用，返回错误时变更会被回滚
+	onChange func() error
+}
+
+// NewMemoryKeyStore 创建进程内应用密钥存储
+func NewMemoryKeyStore() *MemoryKeyStore {
+	return &MemoryKeyStore{apps: make(map[string]*AppKey), tombstones: make(map[string]tombstone)}
+}
+
+// GetAppKey 获取应用
+func (m *MemoryKeyStore) GetAppKey(_ context.Context, appID string) (*AppKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	appKey, ok := m.apps[appID]
+	if !ok {
+		return nil, ErrAppNotFound
+	}
+	return cloneAppKey(appKey), nil
+}
+
+// ListAppKeys 按条件分页查询应用
+func (m *MemoryKeyStore) ListAppKeys(_ context.Context, filter *AppKeyFilter) (*AppKeyPage, error) {
+	f := *filter
+	cursor, err := normalizeFilter(&f)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	var matched []*AppKey
+	for _, appKey := range m.apps {
+		if matchFilter(appKey, &f) && (cursor == nil || afterCursor(appKey, cursor)) {
+			matched = append(matched, cloneAppKey(appKey))
+		}
+	}
+	m.mu.RUnlock()
+
+	sortAppKeys(matched, &f)
+	page := &AppKeyPage{AppKeys: []*AppKey{}}
+	if len(matched) > f.Limit {
+		matched = matched[:f.Limit]
+		page.NextCursor = encodeListCursor(&f, matched[len(matched)-1])
+	}
+	for _, appKey := range matched {
+		if !f.IncludeSecrets {
+			stripSecrets(appKey)
+		}
+		page.AppKeys = append(page.AppKeys, appKey)
+	}
+	return page, nil
+}
+
+// CreateAppKey 创建应用
+func (m *MemoryKeyStore) CreateAppKey(ctx context.Context, appKey *AppKey) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.apps[appKey.AppID]; ok {
+		return fmt.Errorf("%w: %s", ErrAppExists, appKey.AppID)
+	}
+	if _, ok := m.tombstones[appKey.AppID]; ok {
+		return fmt.Errorf("%w: %s", ErrAppPurged, appKey.AppID)
+	}
+
+	stored := cloneAppKey(appKey)
+	stored.ID = m.nextID + 1
+	stored.Version = 1
+	if stored.SignType == "" {
+		stored.SignType = SignTypeMD5
+	}
+	if err := normalizeSecrets(stored); err != nil {
+		return err
+	}
+	m.apps[stored.AppID] = stored
+	events := len(m.events)
+	m.appendEvent(newAppKeyEvent(ctx, ActionCreate, nil, stored, stored.CreateAt))
+	if err := m.changed(); err != nil {
+		delete(m.apps, stored.AppID)
+		m.events = m.events[:events]
+		return err
+	}
+
+	m.nextID = stored.ID
+	appKey.ID = stored.ID
+	appKey.Version = stored.Version
+	appKey.SecretKey = stored.SecretKey
+	appKey.Secrets = cloneAppKey(stored).Secrets
+	return nil
+}
+
+// UpdateAppKey 更新应用的密钥、IP白名单、状态和Attributes
+func (m *MemoryKeyStore) UpdateAppKey(ctx context.Context, appKey *AppKey) error {
+	return m.update(ctx, appKey.AppID, ActionUpdate, func(stored *AppKey) error {
+		update := cloneAppKey(appKey)
+		if _, err := applyStatusChange(stored, contextStatusChange(ctx, update.Status, update.StatusReason), time.Now().Unix()); err != nil {
+			return err
+		}
+		stored.SecretKey = update.SecretKey
+		stored.IPsWhite = update.IPsWhite
+		stored.Attributes = update.Attributes
+		if primary := stored.primarySecret(); primary != nil {
+			primary.Secret = update.SecretKey
+		}
+		return nil
+	})
+}
+
+// PatchAppKey 修改 patch 中非空的字段
+func (m *MemoryKeyStore) PatchAppKey(ctx context.Context, appID string, patch *AppKeyPatch) (int64, error) {
+	var version int64
+	err := m.update(ctx, appID, ActionPatch, func(stored *AppKey) error {
+		if patch.Version != 0 && patch.Version != stored.Version {
+			return fmt.Errorf("%w: 期望版本 %d, 当前版本 %d", ErrVersionConflict, patch.Version, stored.Version)
+		}
+		if patch.SecretKey != nil {
+			stored.SecretKey = *patch.SecretKey
+			if primary := stored.primarySecret(); primary != nil {
+				primary.Secret = *patch.SecretKey
+			}
+		}
+		if patch.IPsWhite != nil {
+			stored.IPsWhite = append([]string{}, patch.IPsWhite...)
+		}
+		if patch.Status != nil {
+			if _, err := applyStatusChange(stored, contextStatusChange(ctx, *patch.Status, patch.StatusReason), time.Now().Unix()); err != nil {
+				return err
+			}
+		}
+		if patch.Attributes != nil {
+			stored.Attributes = cloneValue(patch.Attributes).(map[string]interface{})
+		}
+		version = stored.Version + 1
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+// SetStatus 修改应用状态并记录原因和操作人
+func (m *MemoryKeyStore) SetStatus(ctx context.Context, appID string, change *StatusChange) error {
+	return m.update(ctx, appID, statusAction(change.Status), func(stored *AppKey) error {
+		changed, err := applyStatusChange(stored, change, time.Now().Unix())
+		if err == nil && !changed {
+			return errUnchanged
+		}
+		return err
+	})
+}
+
+// RestoreAppKey 恢复删除期限内的应用
+func (m *MemoryKeyStore) RestoreAppKey(ctx context.Context, appID string, deletedAfter int64, change *StatusChange) error {
+	return m.update(ctx, appID, ActionRestore, func(stored *AppKey) error {
+		return restoreAppKey(stored, deletedAfter, change, time.Now().Unix())
+	})
+}
+
+// PurgeDeleted 清除在 deletedBefore 之前删除的应用
+func (m *MemoryKeyStore) PurgeDeleted(ctx context.Context, deletedBefore, now int64) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	purged := make(map[string]*AppKey)
+	var appIDs []string
+	for appID, appKey := range m.apps {
+		if appKey.Status == StatusDeleted && appKey.DeletedAt > 0 && appKey.DeletedAt <= deletedBefore {
+			purged[appID] = appKey
+			appIDs = append(appIDs, appID)
+		}
+	}
+	if len(appIDs) == 0 {
+		return nil, nil
+	}
+	sort.Strings(appIDs)
+
+	events := len(m.events)
+	for _, appID := range appIDs {
+		delete(m.apps, appID)
+		m.tombstones[appID] = tombstone{AppID: appID, DeletedAt: purged[appID].DeletedAt, PurgedAt: now}
+		m.appendEvent(&AppKeyEvent{AppID: appID, Action: ActionPurge, Actor: actorFromContext(ctx), CreateAt: now})
+	}
+	if err := m.changed(); err != nil {
+		for appID, appKey := range purged {
+			m.apps[appID] = appKey
+			delete(m.tombstones, appID)
+		}
+		m.events = m.events[:events]
+		return nil, err
+	}
+	return appIDs, nil
+}
+
+// AddWhitelistIPs 向IP白名单追加不存在的条目
+func (m *MemoryKeyStore) AddWhitelistIPs(ctx context.Context, appID string, ips []string) error {
+	return m.update(ctx, appID, ActionWhitelist, func(stored *AppKey) error {
+		stored.IPsWhite = uniqueStrings(append(stored.IPsWhite, ips...))
+		return nil
+	})
+}
+
+// RemoveWhitelistIPs 从IP白名单删除条目，不能删除全部条目
+func (m *MemoryKeyStore) RemoveWhitelistIPs(ctx context.Context, appID string, ips []string) error {
+	return m.update(ctx, appID, ActionWhitelist, func(stored *AppKey) error {
+		kept := make([]string, 0, len(stored.IPsWhite))
+		for _, ip := range stored.IPsWhite {
+			if !slices.Contains(ips, ip) {
+				kept = append(kept, ip)
+			}
+		}
+		if len(kept) == 0 && len(stored.IPsWhite) > 0 {
+			return ErrEmptyWhitelist
+		}
+		stored.IPsWhite = kept
+		return nil
+	})
+}
+
+// MergeAttributes 合并Attributes，值为 nil 的键会被删除
+func (m *MemoryKeyStore) MergeAttributes(ctx context.Context, appID string, attributes map[string]interface{}) error {
+	return m.update(ctx, appID, ActionAttributes, func(stored *AppKey) error {
+		stored.Attributes = mergeAttributes(stored.Attributes, attributes)
+		return nil
+	})
+}
+
+// SetSignTypes 设置应用的签名算法
+func (m *MemoryKeyStore) SetSignTypes(ctx context.Context, appID string, signType SignType, allowed []SignType) error {
+	return m.update(ctx, appID, ActionSignTypes, func(stored *AppKey) error {
+		stored.SignType = signType
+		stored.AllowedSignTypes = append([]SignType{}, allowed...)
+		return nil
+	})
+}
+
+// SetPublicKey 设置应用验签使用的PEM公钥
+func (m *MemoryKeyStore) SetPublicKey(ctx context.Context, appID, publicKey string) error {
+	return m.update(ctx, appID, ActionPublicKey, func(stored *AppKey) error {
+		stored.PublicKey = publicKey
+		return nil
+	})
+}
+
+// AddSecret 添加密钥
+func (m *MemoryKeyStore) AddSecret(ctx context.Context, appID string, secret *AppSecret) error {
+	return m.update(ctx, appID, ActionAddSecret, func(stored *AppKey) error {
+		if len(stored.Secrets) == 0 {
+			// 文件存储中的旧数据没有密钥列表
+			if err := normalizeSecrets(stored); err != nil {
+				return err
+			}
+		}
+		for _, existing := range stored.Secrets {
+			if existing.KeyID == secret.KeyID {
+				return fmt.Errorf("%w: %s", ErrSecretExists, secret.KeyID)
+			}
+		}
+
+		if secret.Primary {
+			for i := range stored.Secrets {
+				stored.Secrets[i].Primary = false
+			}
+			stored.SecretKey = secret.Secret
+		}
+		stored.Secrets = append(stored.Secrets, *secret)
+		return nil
+	})
+}
+
+// RemoveSecret 删除密钥
+func (m *MemoryKeyStore) RemoveSecret(ctx context.Context, appID, keyID string) error {
+	return m.update(ctx, appID, ActionRemoveSecret, func(stored *AppKey) error {
+		for i, secret := range stored.Secrets {
+			if secret.KeyID != keyID {
+				continue
+			}
+			if secret.Primary {
+				return ErrPrimarySecret
+			}
+			stored.Secrets = append(stored.Secrets[:i], stored.Secrets[i+1:]...)
+			return nil
+		}
+		return ErrSecretNotFound
+	})
+}
+
+// SetPrimarySecret 设置主密钥
+func (m *MemoryKeyStore) SetPrimarySecret(ctx context.Context, appID, keyID string) error {
+	return m.update(ctx, appID, ActionPrimarySecret, func(stored *AppKey) error {
+		index := -1
+		for i, secret := range stored.Secrets {
+			if secret.KeyID == keyID {
+				index = i
+			}
+		}
+		if index < 0 {
+			return ErrSecretNotFound
+		}
+
+		for i := range stored.Secrets {
+			stored.Secrets[i].Primary = i == index
+		}
+		stored.SecretKey = stored.Secrets[index].Secret
+		return nil
+	})
+}
+
+// RotateSecret 将 secret 设为主密钥，原主密钥最晚在 notAfter 失效
+func (m *MemoryKeyStore) RotateSecret(ctx context.Context, appID string, secret *AppSecret, notAfter int64) (string, error) {
+	var previousKeyID string
+	err := m.update(ctx, appID, ActionRotateSecret, func(stored *AppKey) error {
+		if len(stored.Secrets) == 0 {
+			if err := normalizeSecrets(stored); err != nil {
+				return err
+			}
+		}
+		for i := range stored.Secrets {
+			old := &stored.Secrets[i]
+			if old.KeyID == secret.KeyID {
+				return fmt.Errorf("%w: %s", ErrSecretExists, secret.KeyID)
+			}
+			if old.Primary {
+				previousKeyID = old.KeyID
+				old.Primary = false
+				if old.NotAfter == 0 || old.NotAfter > notAfter {
+					old.NotAfter = notAfter
+				}
+			}
+		}
+
+		stored.Secrets = append(stored.Secrets, *secret)
+		stored.SecretKey = secret.Secret
+		return nil
+	})
+	return previousKeyID, err
+}
+
+// DeleteExpiredSecrets 删除全部应用中已失效的非主密钥
+func (m *MemoryKeyStore) DeleteExpiredSecrets(ctx context.Context, now int64) ([]RetiredSecret, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	events := len(m.events)
+	var retired []RetiredSecret
+	old := make(map[string]*AppKey)
+	for appID, appKey := range m.apps {
+		kept := make([]AppSecret, 0, len(appKey.Secrets))
+		for _, secret := range appKey.Secrets {
+			if !secret.Primary && secret.NotAfter != 0 && secret.NotAfter <= now {
+				retired = append(retired, RetiredSecret{AppID: appID, KeyID: secret.KeyID})
+				continue
+			}
+			kept = append(kept, secret)
+		}
+		if len(kept) == len(appKey.Secrets) {
+			continue
+		}
+
+		stored := cloneAppKey(appKey)
+		stored.Secrets = kept
+		stored.UpdateAt = &now
+		stored.Version++
+		old[appID] = appKey
+		m.apps[appID] = stored
+		m.appendEvent(newAppKeyEvent(ctx, ActionExpireSecrets, appKey, stored, now))
+	}
+	if len(retired) == 0 {
+		return nil, nil
+	}
+
+	if err := m.changed(); err != nil {
+		for appID, appKey := range old {
+			m.apps[appID] = appKey
+		}
+		m.events = m.events[:events]
+		return nil, err
+	}
+	return retired, nil
+}
+
+// errUnchanged fn 返回该错误时 update 不修改记录，也不返回错误
+var errUnchanged = errors.New("unchanged")
+
+// update 在副本上修改应用，成功后替换原记录
+func (m *MemoryKeyStore) update(ctx context.Context, appID string, action AppKeyAction, fn func(stored *AppKey) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	old, ok := m.apps[appID]
+	if !ok {
+		return ErrAppNotFound
+	}
+
+	stored := cloneAppKey(old)
+	if err := fn(stored); err != nil {
+		if err == errUnchanged {
+			return nil
+		}
+		return err
+	}
+	now := time.Now().Unix()
+	stored.UpdateAt = &now
+	stored.Version++
+
+	m.apps[appID] = stored
+	events := len(m.events)
+	m.appendEvent(newAppKeyEvent(ctx, action, old, stored, now))
+	if err := m.changed(); err != nil {
+		m.apps[appID] = old
+		m.events = m.events[:events]
+		return err
+	}
+	return nil
+}
+
+// appendEvent 追加审计记录，event 为 nil 时不做任何操作；调用方需持有写锁
+func (m *MemoryKeyStore) appendEvent(event *AppKeyEvent) {
+	if event == nil {
+		return
+	}
+	event.ID = int64(len(m.events)) + 1
+	m.events = append(m.events, *event)
+}
+
+// History 返回应用的全部审计记录
+func (m *MemoryKeyStore) History(_ context.Context, appID string) ([]AppKeyEvent, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.history(appID), nil
+}
+
+// GetAppKeyAt 返回应用在 at 时刻的脱敏快照
+func (m *MemoryKeyStore) GetAppKeyAt(_ context.Context, appID string, at int64) (*AppKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return appKeyAt(m.history(appID), at)
+}
+
+// history 返回应用审计记录的副本，调用方需持有读锁
+func (m *MemoryKeyStore) history(appID string) []AppKeyEvent {
+	events := []AppKeyEvent{}
+	for _, event := range m.events {
+		if event.AppID != appID {
+			continue
+		}
+		if event.Snapshot != nil {
+			event.Snapshot = cloneAppKey(event.Snapshot)
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func (m *MemoryKeyStore) changed() error {
+	if m.onChange == nil {
+		return nil
+	}
+	return m.onChange()
+}
+
+// cloneAppKey 深拷贝应用
+func cloneAppKey(appKey *AppKey) *AppKey {
+	c := *appKey
+	if appKey.IPsWhite != nil {
+		c.IPsWhite = append([]string{}, appKey.IPsWhite...)
+	}
+	if appKey.AllowedSignTypes != nil {
+		c.AllowedSignTypes = append([]SignType{}, appKey.AllowedSignTypes...)
+	}
+	if appKey.Secrets != nil {
+		c.Secrets = append([]AppSecret{}, appKey.Secrets...)
+	}
+	if appKey.UpdateAt != nil {
+		updateAt := *appKey.UpdateAt
+		c.UpdateAt = &updateAt
+	}
+	if appKey.Attributes != nil {
+		c.Attributes = cloneValue(appKey.Attributes).(map[string]interface{})
+	}
+	return &c
+}
+
+// cloneValue 深拷贝JSON风格的值
+func cloneValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			m[k] = cloneValue(item)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(val))
+		for i, item := range val {
+			s[i] = cloneValue(item)
+		}
+		return s
+	default:
+		return val
+	}
+}
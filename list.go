@@ -0,0 +1,270 @@
+package go_signature_sdk
+
+import (
+	"cmp"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// AppKeySort ListAppKeys 的排序字段
+type AppKeySort string
+
+const (
+	SortByID       AppKeySort = "id" // 默认，即创建顺序
+	SortByAppID    AppKeySort = "app_id"
+	SortByCreateAt AppKeySort = "create_at"
+	SortByUpdateAt AppKeySort = "update_at" // 未修改过的应用按0排序
+)
+
+// ListAppKeys 分页大小
+const (
+	DefaultListLimit = 50
+	MaxListLimit     = 1000
+)
+
+// AppKeyFilter ListAppKeys 的查询条件，零值表示不限制
+type AppKeyFilter struct {
+	Status []AppStatus // 状态为其中之一
+	// IncludeDeleted 未指定 Status 时是否包含已删除的应用，默认不包含
+	IncludeDeleted bool
+
+	// 时间范围为 [After, Before)，单位秒
+	CreatedAfter  int64
+	CreatedBefore int64
+	UpdatedAfter  int64
+	UpdatedBefore int64
+
+	// WhitelistIP 白名单中有该IP或包含该IP的CIDR，白名单为空的应用不匹配
+	WhitelistIP string
+	// Attributes Attributes 包含这些键值，如 {"tenant": "x"}，与PostgreSQL的 @> 语义一致
+	Attributes map[string]interface{}
+
+	SortBy     AppKeySort
+	Descending bool
+	Limit      int    // 每页数量，默认 DefaultListLimit，最大 MaxListLimit
+	Cursor     string // 上一页返回的 NextCursor，为空时从第一页开始
+
+	// IncludeSecrets 为true时返回 SecretKey 和 Secrets，默认不返回
+	// PostgreSQL存储只在返回密钥时解密并校验 row_mac，全量校验使用 VerifyAppKeys
+	IncludeSecrets bool
+}
+
+// AppKeyPage 一页应用
+type AppKeyPage struct {
+	AppKeys    []*AppKey `json:"app_keys"`
+	NextCursor string    `json:"next_cursor,omitempty"` // 为空表示没有下一页
+	// Tampered 本页中 row_mac 校验失败的应用，这些应用不在 AppKeys 中
+	Tampered []TamperedRow `json:"tampered,omitempty"`
+}
+
+// listCursor 分页游标，记录上一页最后一个应用的排序值
+type listCursor struct {
+	Sort AppKeySort `json:"s"`
+	Desc bool       `json:"d,omitempty"`
+	Int  int64      `json:"i,omitempty"`
+	Str  string     `json:"a,omitempty"`
+	ID   int        `json:"id"`
+}
+
+// ListAppKeys 按条件分页查询应用，使用游标（keyset）分页，翻页期间新增的应用不会导致重复或遗漏
+func (s *SignatureSDK) ListAppKeys(ctx context.Context, filter AppKeyFilter) (*AppKeyPage, error) {
+	return s.store.ListAppKeys(ctx, &filter)
+}
+
+// normalizeFilter 校验查询条件并填充默认值，返回解析后的游标
+func normalizeFilter(filter *AppKeyFilter) (*listCursor, error) {
+	switch filter.SortBy {
+	case "":
+		filter.SortBy = SortByID
+	case SortByID, SortByAppID, SortByCreateAt, SortByUpdateAt:
+	default:
+		return nil, fmt.Errorf("%w: 不支持的排序字段 %s", ErrInvalidRequest, filter.SortBy)
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultListLimit
+	}
+	if filter.Limit > MaxListLimit {
+		filter.Limit = MaxListLimit
+	}
+	if filter.WhitelistIP != "" && net.ParseIP(filter.WhitelistIP) == nil {
+		return nil, fmt.Errorf("%w: 无效的IP %q", ErrInvalidRequest, filter.WhitelistIP)
+	}
+	if filter.Cursor == "" {
+		return nil, nil
+	}
+	return decodeListCursor(filter)
+}
+
+// encodeListCursor 根据一页的最后一个应用生成游标
+func encodeListCursor(filter *AppKeyFilter, last *AppKey) string {
+	c := listCursor{Sort: filter.SortBy, Desc: filter.Descending, ID: last.ID}
+	switch filter.SortBy {
+	case SortByAppID:
+		c.Str = last.AppID
+	case SortByCreateAt:
+		c.Int = last.CreateAt
+	case SortByUpdateAt:
+		c.Int = updateAtOrZero(last)
+	}
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeListCursor 解析游标，排序方式与游标不一致时返回错误
+func decodeListCursor(filter *AppKeyFilter) (*listCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(filter.Cursor)
+	var c listCursor
+	if err == nil {
+		err = json.Unmarshal(data, &c)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: 无效的游标", ErrInvalidRequest)
+	}
+	if c.Sort != filter.SortBy || c.Desc != filter.Descending {
+		return nil, fmt.Errorf("%w: 游标与排序方式不一致", ErrInvalidRequest)
+	}
+	return &c, nil
+}
+
+func updateAtOrZero(appKey *AppKey) int64 {
+	if appKey.UpdateAt == nil {
+		return 0
+	}
+	return *appKey.UpdateAt
+}
+
+// stripSecrets 清除应用中的密钥
+func stripSecrets(appKey *AppKey) {
+	appKey.SecretKey = ""
+	appKey.Secrets = nil
+}
+
+// matchFilter 内存存储中判断应用是否满足查询条件
+func matchFilter(appKey *AppKey, filter *AppKeyFilter) bool {
+	if len(filter.Status) == 0 && !filter.IncludeDeleted && appKey.Status == StatusDeleted {
+		return false
+	}
+	if len(filter.Status) > 0 {
+		matched := false
+		for _, st := range filter.Status {
+			matched = matched || appKey.Status == st
+		}
+		if !matched {
+			return false
+		}
+	}
+	if !inRange(appKey.CreateAt, filter.CreatedAfter, filter.CreatedBefore) {
+		return false
+	}
+	if (filter.UpdatedAfter != 0 || filter.UpdatedBefore != 0) &&
+		(appKey.UpdateAt == nil || !inRange(*appKey.UpdateAt, filter.UpdatedAfter, filter.UpdatedBefore)) {
+		return false
+	}
+	if filter.WhitelistIP != "" && !whitelistContains(appKey.IPsWhite, filter.WhitelistIP, net.ParseIP(filter.WhitelistIP)) {
+		return false
+	}
+	if len(filter.Attributes) > 0 && !jsonContains(appKey.Attributes, filter.Attributes) {
+		return false
+	}
+	return true
+}
+
+// inRange 判断 v 是否在 [after, before) 内，边界为0表示不限制
+func inRange(v, after, before int64) bool {
+	return (after == 0 || v >= after) && (before == 0 || v < before)
+}
+
+// jsonContains 与PostgreSQL jsonb @> 相同的包含判断：对象逐键包含，数组中每个元素都能在目标数组中找到
+func jsonContains(target, query interface{}) bool {
+	switch q := query.(type) {
+	case map[string]interface{}:
+		t, ok := target.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for k, v := range q {
+			tv, ok := t[k]
+			if !ok || !jsonContains(tv, v) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		t, ok := target.([]interface{})
+		if !ok {
+			return false
+		}
+		for _, v := range q {
+			found := false
+			for _, tv := range t {
+				if jsonContains(tv, v) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(normalizeJSONValue(target), normalizeJSONValue(query))
+	}
+}
+
+// normalizeJSONValue 将数字统一为 float64，与JSON解码后的类型一致
+func normalizeJSONValue(v interface{}) interface{} {
+	switch v.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, json.Number:
+		data, _ := json.Marshal(v)
+		var f float64
+		json.Unmarshal(data, &f)
+		return f
+	default:
+		return v
+	}
+}
+
+// sortAppKeys 内存存储中按查询条件排序，排序值相同时按ID
+func sortAppKeys(appKeys []*AppKey, filter *AppKeyFilter) {
+	sort.Slice(appKeys, func(i, j int) bool {
+		c := compareAppKeys(appKeys[i], appKeys[j], filter.SortBy)
+		if filter.Descending {
+			return c > 0
+		}
+		return c < 0
+	})
+}
+
+// compareAppKeys 按排序字段和ID比较两个应用
+func compareAppKeys(a, b *AppKey, sortBy AppKeySort) int {
+	var c int
+	switch sortBy {
+	case SortByAppID:
+		c = strings.Compare(a.AppID, b.AppID)
+	case SortByCreateAt:
+		c = cmp.Compare(a.CreateAt, b.CreateAt)
+	case SortByUpdateAt:
+		c = cmp.Compare(updateAtOrZero(a), updateAtOrZero(b))
+	}
+	if c == 0 {
+		c = cmp.Compare(a.ID, b.ID)
+	}
+	return c
+}
+
+// afterCursor 内存存储中判断应用是否在游标之后
+func afterCursor(appKey *AppKey, cursor *listCursor) bool {
+	last := &AppKey{ID: cursor.ID, AppID: cursor.Str, CreateAt: cursor.Int, UpdateAt: &cursor.Int}
+	c := compareAppKeys(appKey, last, cursor.Sort)
+	if cursor.Desc {
+		return c < 0
+	}
+	return c > 0
+}
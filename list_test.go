@@ -0,0 +1,119 @@
+package go_signature_sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// TestListAppKeys 测试内存存储的应用列表
+func TestListAppKeys(t *testing.T) {
+	testListAppKeys(t, createMemorySDK(t))
+}
+
+// TestSDKListAppKeys 测试PostgreSQL存储的应用列表
+func TestSDKListAppKeys(t *testing.T) {
+	sdk, db := createTestSDK(t)
+	defer teardownTestDB(t, db)
+	testListAppKeys(t, sdk)
+}
+
+func testListAppKeys(t *testing.T, sdk *SignatureSDK) {
+	ctx := context.Background()
+	for i := 0; i < 7; i++ {
+		appID := fmt.Sprintf("test_app_list_%d", i)
+		tenant := "a"
+		if i%2 == 1 {
+			tenant = "b"
+		}
+		attributes := map[string]interface{}{"tenant": tenant, "tags": []interface{}{"x", fmt.Sprint(i)}}
+		if err := sdk.CreateAppKey(appID, "test_secret", []string{"10.0.0.0/8", fmt.Sprintf("192.168.1.%d", i)}, attributes); err != nil {
+			t.Fatalf("创建测试应用失败: %v", err)
+		}
+	}
+	if err := sdk.SetStatus(ctx, "test_app_list_3", StatusChange{Status: StatusSuspended}); err != nil {
+		t.Fatalf("暂停应用失败: %v", err)
+	}
+
+	// 翻页遍历全部应用，不重复不遗漏
+	var ids []string
+	filter := AppKeyFilter{Limit: 3}
+	for pages := 0; ; pages++ {
+		if pages > 5 {
+			t.Fatal("翻页未结束")
+		}
+		page, err := sdk.ListAppKeys(ctx, filter)
+		if err != nil {
+			t.Fatalf("查询应用失败: %v", err)
+		}
+		for _, appKey := range page.AppKeys {
+			if appKey.SecretKey != "" || appKey.Secrets != nil {
+				t.Errorf("列表默认不应返回密钥: %s", appKey.AppID)
+			}
+			ids = append(ids, appKey.AppID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		filter.Cursor = page.NextCursor
+	}
+	if len(ids) != 7 || ids[0] != "test_app_list_0" || ids[6] != "test_app_list_6" {
+		t.Errorf("翻页结果错误: %v", ids)
+	}
+
+	list := func(filter AppKeyFilter) []string {
+		t.Helper()
+		page, err := sdk.ListAppKeys(ctx, filter)
+		if err != nil {
+			t.Fatalf("查询应用失败: %v", err)
+		}
+		var ids []string
+		for _, appKey := range page.AppKeys {
+			ids = append(ids, appKey.AppID)
+		}
+		return ids
+	}
+
+	if ids := list(AppKeyFilter{SortBy: SortByAppID, Descending: true, Limit: 2}); len(ids) != 2 || ids[0] != "test_app_list_6" {
+		t.Errorf("倒序结果错误: %v", ids)
+	}
+	if ids := list(AppKeyFilter{Status: []AppStatus{StatusSuspended}}); len(ids) != 1 || ids[0] != "test_app_list_3" {
+		t.Errorf("状态过滤错误: %v", ids)
+	}
+	if ids := list(AppKeyFilter{Attributes: map[string]interface{}{"tenant": "b"}}); len(ids) != 3 {
+		t.Errorf("Attributes过滤错误: %v", ids)
+	}
+	if ids := list(AppKeyFilter{Attributes: map[string]interface{}{"tags": []interface{}{"4"}}}); len(ids) != 1 || ids[0] != "test_app_list_4" {
+		t.Errorf("Attributes数组过滤错误: %v", ids)
+	}
+	if ids := list(AppKeyFilter{WhitelistIP: "192.168.1.2"}); len(ids) != 1 || ids[0] != "test_app_list_2" {
+		t.Errorf("白名单IP过滤错误: %v", ids)
+	}
+	if ids := list(AppKeyFilter{WhitelistIP: "10.1.2.3"}); len(ids) != 7 {
+		t.Errorf("白名单CIDR过滤错误: %v", ids)
+	}
+	if ids := list(AppKeyFilter{UpdatedAfter: 1}); len(ids) != 1 || ids[0] != "test_app_list_3" {
+		t.Errorf("更新时间过滤错误: %v", ids)
+	}
+	if ids := list(AppKeyFilter{CreatedBefore: 1}); len(ids) != 0 {
+		t.Errorf("创建时间过滤错误: %v", ids)
+	}
+
+	page, err := sdk.ListAppKeys(ctx, AppKeyFilter{IncludeSecrets: true, Limit: 1})
+	if err != nil || len(page.AppKeys) != 1 || page.AppKeys[0].SecretKey != "test_secret" || len(page.AppKeys[0].Secrets) != 1 {
+		t.Errorf("IncludeSecrets 未返回密钥: %+v, %v", page, err)
+	}
+
+	invalid := []AppKeyFilter{
+		{SortBy: "secret_key"},
+		{WhitelistIP: "not_an_ip"},
+		{Cursor: "!!!"},
+		{Cursor: page.NextCursor, SortBy: SortByAppID},
+	}
+	for _, filter := range invalid {
+		if _, err := sdk.ListAppKeys(ctx, filter); !errors.Is(err, ErrInvalidRequest) {
+			t.Errorf("期望参数错误: %+v, 实际: %v", filter, err)
+		}
+	}
+}
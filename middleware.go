@@ -0,0 +1,331 @@
+package go_signature_sdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// 签名相关的HTTP请求头
+const (
+	HeaderAppID     = "X-App-ID"
+	HeaderTimestamp = "X-Timestamp"
+	HeaderNonce     = "X-Nonce"
+	HeaderSign      = "X-Sign"
+)
+
+// DefaultMaxBodySize 中间件读取请求体的默认上限
+const DefaultMaxBodySize = 10 << 20
+
+type contextKey int
+
+const (
+	appKeyContextKey contextKey = iota
+	keyIDContextKey
+)
+
+// AppKeyFromContext 获取中间件验签通过的应用
+func AppKeyFromContext(ctx context.Context) (*AppKey, bool) {
+	appKey, ok := ctx.Value(appKeyContextKey).(*AppKey)
+	return appKey, ok
+}
+
+// KeyIDFromContext 获取中间件验签时匹配的密钥ID
+func KeyIDFromContext(ctx context.Context) string {
+	keyID, _ := ctx.Value(keyIDContextKey).(string)
+	return keyID
+}
+
+// ErrorHandler 验签失败时的响应处理
+type ErrorHandler func(w http.ResponseWriter, r *http.Request, err error)
+
+// MiddlewareOption 中间件选项
+type MiddlewareOption func(*middlewareOptions)
+
+type middlewareOptions struct {
+	errorHandler   ErrorHandler
+	clientIP       func(r *http.Request) string
+	trustedProxies []*net.IPNet
+	maxBodySize    int64
+}
+
+// WithErrorHandler 自定义验签失败的响应，默认为 DefaultErrorHandler
+func WithErrorHandler(handler ErrorHandler) MiddlewareOption {
+	return func(o *middlewareOptions) {
+		o.errorHandler = handler
+	}
+}
+
+// WithClientIPFunc 自定义客户端IP的获取方式
+func WithClientIPFunc(f func(r *http.Request) string) MiddlewareOption {
+	return func(o *middlewareOptions) {
+		o.clientIP = f
+	}
+}
+
+// WithTrustedProxies 设置可信代理（单IP或CIDR），来自可信代理的请求使用 X-Forwarded-For / X-Real-IP 中的客户端IP
+func WithTrustedProxies(proxies ...string) MiddlewareOption {
+	return func(o *middlewareOptions) {
+		for _, proxy := range proxies {
+			if !strings.Contains(proxy, "/") {
+				if ip := net.ParseIP(proxy); ip != nil && ip.To4() != nil {
+					proxy += "/32"
+				} else {
+					proxy += "/128"
+				}
+			}
+			if _, ipNet, err := net.ParseCIDR(proxy); err == nil {
+				o.trustedProxies = append(o.trustedProxies, ipNet)
+			}
+		}
+	}
+}
+
+// WithMaxBodySize 设置读取请求体的上限
+func WithMaxBodySize(n int64) MiddlewareOption {
+	return func(o *middlewareOptions) {
+		o.maxBodySize = n
+	}
+}
+
+// HTTPMiddleware net/http 验签中间件
+// 签名参数为查询参数、表单或JSON请求体中的字段，加上 X-Timestamp、X-Nonce 请求头；
+// 验签通过后可通过 AppKeyFromContext 获取应用
+func HTTPMiddleware(sdk *SignatureSDK, opts ...MiddlewareOption) func(http.Handler) http.Handler {
+	o := &middlewareOptions{
+		errorHandler: DefaultErrorHandler,
+		maxBodySize:  DefaultMaxBodySize,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.clientIP == nil {
+		o.clientIP = o.defaultClientIP
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			params, err := o.verifyParams(r)
+			if err != nil {
+				o.errorHandler(w, r, err)
+				return
+			}
+
+			result, err := sdk.Verify(r.Context(), params)
+			if err != nil {
+				o.errorHandler(w, r, err)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), appKeyContextKey, result.AppKey)
+			ctx = context.WithValue(ctx, keyIDContextKey, result.KeyID)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// verifyParams 从请求中构建验签参数
+func (o *middlewareOptions) verifyParams(r *http.Request) (*VerifyParams, error) {
+	data, err := extractRequestParams(r, o.maxBodySize)
+	if err != nil {
+		return nil, err
+	}
+
+	params := &VerifyParams{
+		AppID:    r.Header.Get(HeaderAppID),
+		Nonce:    r.Header.Get(HeaderNonce),
+		Sign:     r.Header.Get(HeaderSign),
+		Data:     data,
+		ClientIP: o.clientIP(r),
+	}
+	if params.AppID == "" {
+		params.AppID, _ = data["app_id"].(string)
+	}
+	if params.AppID == "" {
+		return nil, fmt.Errorf("%w: 缺少 %s", ErrInvalidRequest, HeaderAppID)
+	}
+	if params.Sign == "" {
+		if _, ok := data["sign"].(string); !ok {
+			return nil, fmt.Errorf("%w: 缺少 %s", ErrInvalidRequest, HeaderSign)
+		}
+	}
+
+	if timestamp := r.Header.Get(HeaderTimestamp); timestamp != "" {
+		if params.Timestamp, err = parseTimestamp(timestamp); err != nil {
+			return nil, err
+		}
+	}
+	return params, nil
+}
+
+// extractRequestParams 提取查询参数、表单和JSON请求体中的参数，请求体会被还原以供后续处理器读取
+func extractRequestParams(r *http.Request, maxBodySize int64) (map[string]interface{}, error) {
+	data := make(map[string]interface{})
+	mergeValues(data, r.URL.Query())
+
+	if r.Body == nil || r.Body == http.NoBody {
+		return data, nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		if err := r.ParseMultipartForm(maxBodySize); err != nil {
+			return nil, fmt.Errorf("%w: 解析表单失败: %v", ErrInvalidRequest, err)
+		}
+		mergeValues(data, r.MultipartForm.Value)
+		return data, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize+1))
+	r.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("%w: 读取请求体失败: %v", ErrInvalidRequest, err)
+	}
+	if int64(len(body)) > maxBodySize {
+		return nil, fmt.Errorf("%w: 请求体过大", ErrInvalidRequest)
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	if err := mergeBodyParams(data, mediaType, body); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// mergeBodyParams 合并表单或JSON请求体中的参数，其他类型的请求体不参与签名
+func mergeBodyParams(data map[string]interface{}, mediaType string, body []byte) error {
+	switch {
+	case mediaType == "application/x-www-form-urlencoded":
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return fmt.Errorf("%w: 解析表单失败: %v", ErrInvalidRequest, err)
+		}
+		mergeValues(data, values)
+	case isJSONMediaType(mediaType):
+		if len(bytes.TrimSpace(body)) == 0 {
+			break
+		}
+		fields, err := decodeJSONObject(body)
+		if err != nil {
+			return err
+		}
+		for k, v := range fields {
+			data[k] = v
+		}
+	}
+	return nil
+}
+
+func isJSONMediaType(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// decodeJSONObject 解析JSON对象
+// 使用 json.Number 保留数字原文，避免大数被格式化为科学计数法
+func decodeJSONObject(body []byte) (map[string]interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var fields map[string]interface{}
+	if err := decoder.Decode(&fields); err != nil {
+		return nil, fmt.Errorf("%w: 请求体不是JSON对象: %v", ErrInvalidRequest, err)
+	}
+	return fields, nil
+}
+
+// mergeValues 合并表单值，单值为字符串，多值为数组
+func mergeValues(data map[string]interface{}, values map[string][]string) {
+	for k, v := range values {
+		switch len(v) {
+		case 0:
+		case 1:
+			data[k] = v[0]
+		default:
+			items := make([]interface{}, len(v))
+			for i, item := range v {
+				items[i] = item
+			}
+			data[k] = items
+		}
+	}
+}
+
+// defaultClientIP 获取客户端IP，仅信任来自可信代理的转发头
+func (o *middlewareOptions) defaultClientIP(r *http.Request) string {
+	remoteIP := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		remoteIP = host
+	}
+	if !o.isTrustedProxy(remoteIP) {
+		return remoteIP
+	}
+
+	// 从右向左跳过可信代理，第一个不可信的地址即客户端IP
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		hops := strings.Split(forwarded, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if !o.isTrustedProxy(hop) || i == 0 {
+				return hop
+			}
+		}
+	}
+	if realIP := strings.TrimSpace(r.Header.Get("X-Real-IP")); realIP != "" {
+		return realIP
+	}
+	return remoteIP
+}
+
+func (o *middlewareOptions) isTrustedProxy(ip string) bool {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+	for _, ipNet := range o.trustedProxies {
+		if ipNet.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultErrorHandler 默认的验签失败响应，返回JSON {"code": 状态码, "message": 错误信息}
+func DefaultErrorHandler(w http.ResponseWriter, r *http.Request, err error) {
+	status := StatusCodeForError(err)
+	message := err.Error()
+	if status == http.StatusInternalServerError {
+		// 不向客户端暴露内部错误细节
+		message = http.StatusText(status)
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"code":    status,
+		"message": message,
+	})
+}
+
+// StatusCodeForError 返回验签错误对应的HTTP状态码
+func StatusCodeForError(err error) int {
+	switch {
+	case errors.Is(err, ErrInvalidRequest):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrIPNotAllowed), errors.Is(err, ErrAppDisabled):
+		return http.StatusForbidden
+	case errors.Is(err, ErrAppNotFound), errors.Is(err, ErrInvalidCredential), errors.Is(err, ErrInvalidSign),
+		errors.Is(err, ErrExpiredRequest), errors.Is(err, ErrMissingTimestamp),
+		errors.Is(err, ErrMissingNonce), errors.Is(err, ErrReplayedRequest),
+		errors.Is(err, ErrSignTypeNotAllowed), errors.Is(err, ErrUnsupportedSignType):
+		return http.StatusUnauthorized
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,218 @@
+package go_signature_sdk
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestExtractRequestParams 测试请求参数提取
+func TestExtractRequestParams(t *testing.T) {
+	testCases := []struct {
+		name        string
+		method      string
+		target      string
+		contentType string
+		body        string
+		expected    string
+	}{
+		{
+			name:     "查询参数",
+			method:   http.MethodGet,
+			target:   "/api/user?user_id=12345&action=login",
+			expected: "action=login&user_id=12345",
+		},
+		{
+			name:     "多值查询参数",
+			method:   http.MethodGet,
+			target:   "/api/user?id=1&id=2",
+			expected: "id[0]=1&id[1]=2",
+		},
+		{
+			name:        "表单",
+			method:      http.MethodPost,
+			target:      "/api/user?action=update",
+			contentType: "application/x-www-form-urlencoded",
+			body:        "user_id=12345&name=test",
+			expected:    "action=update&name=test&user_id=12345",
+		},
+		{
+			name:        "JSON请求体",
+			method:      http.MethodPost,
+			target:      "/api/user",
+			contentType: "application/json; charset=utf-8",
+			body:        `{"user":{"id":123,"name":"test"},"amount":10000000,"price":1.5}`,
+			expected:    "amount=10000000&price=1.5&user.id=123&user.name=test",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(tc.method, tc.target, strings.NewReader(tc.body))
+			if tc.contentType != "" {
+				r.Header.Set("Content-Type", tc.contentType)
+			}
+
+			data, err := extractRequestParams(r, DefaultMaxBodySize)
+			if err != nil {
+				t.Fatalf("提取参数失败: %v", err)
+			}
+			if got := buildCanonicalString(data); got != tc.expected {
+				t.Errorf("期望: %s, 实际: %s", tc.expected, got)
+			}
+
+			// 请求体可被后续处理器再次读取
+			body, _ := io.ReadAll(r.Body)
+			if string(body) != tc.body {
+				t.Errorf("请求体未还原: %s", body)
+			}
+		})
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("[1,2]"))
+	r.Header.Set("Content-Type", "application/json")
+	if _, err := extractRequestParams(r, DefaultMaxBodySize); StatusCodeForError(err) != http.StatusBadRequest {
+		t.Errorf("期望请求参数错误, 实际: %v", err)
+	}
+}
+
+// TestClientIP 测试客户端IP获取
+func TestClientIP(t *testing.T) {
+	o := &middlewareOptions{}
+	WithTrustedProxies("10.0.0.1", "172.16.0.0/12")(o)
+
+	testCases := []struct {
+		name       string
+		remoteAddr string
+		forwarded  string
+		realIP     string
+		expected   string
+	}{
+		{
+			name:       "直连请求",
+			remoteAddr: "203.0.113.5:4321",
+			expected:   "203.0.113.5",
+		},
+		{
+			name:       "不可信来源的转发头被忽略",
+			remoteAddr: "203.0.113.5:4321",
+			forwarded:  "127.0.0.1",
+			expected:   "203.0.113.5",
+		},
+		{
+			name:       "可信代理",
+			remoteAddr: "10.0.0.1:4321",
+			forwarded:  "127.0.0.1, 198.51.100.7, 172.16.3.4",
+			expected:   "198.51.100.7",
+		},
+		{
+			name:       "X-Real-IP",
+			remoteAddr: "10.0.0.1:4321",
+			realIP:     "198.51.100.8",
+			expected:   "198.51.100.8",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tc.remoteAddr
+			if tc.forwarded != "" {
+				r.Header.Set("X-Forwarded-For", tc.forwarded)
+			}
+			if tc.realIP != "" {
+				r.Header.Set("X-Real-IP", tc.realIP)
+			}
+			if got := o.defaultClientIP(r); got != tc.expected {
+				t.Errorf("期望: %s, 实际: %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+// TestHTTPMiddlewareErrors 测试中间件错误响应
+func TestHTTPMiddlewareErrors(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("验签失败时不应调用后续处理器")
+	})
+
+	// 缺少应用ID
+	rec := httptest.NewRecorder()
+	HTTPMiddleware(&SignatureSDK{})(next).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/user", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("期望状态码 %d, 实际 %d", http.StatusBadRequest, rec.Code)
+	}
+	var resp map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || resp["message"] == "" {
+		t.Errorf("响应格式错误: %s", rec.Body.String())
+	}
+
+	// 自定义错误响应
+	rec = httptest.NewRecorder()
+	handler := HTTPMiddleware(&SignatureSDK{}, WithErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+		w.WriteHeader(http.StatusTeapot)
+	}))(next)
+	r := httptest.NewRequest(http.MethodGet, "/api/user", nil)
+	r.Header.Set(HeaderAppID, "test_app")
+	handler.ServeHTTP(rec, r)
+	if rec.Code != http.StatusTeapot {
+		t.Errorf("期望状态码 %d, 实际 %d", http.StatusTeapot, rec.Code)
+	}
+}
+
+// TestHTTPMiddleware 测试中间件验签
+func TestHTTPMiddleware(t *testing.T) {
+	sdk := createMemorySDK(t)
+
+	appID := "test_app_http"
+	secretKey := "test_secret_http"
+	if err := sdk.CreateAppKey(appID, secretKey, []string{"192.0.2.1"}, map[string]interface{}{}); err != nil {
+		t.Fatalf("创建测试应用失败: %v", err)
+	}
+
+	handler := HTTPMiddleware(sdk)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		appKey, ok := AppKeyFromContext(r.Context())
+		if !ok || appKey.AppID != appID {
+			t.Error("上下文中没有应用信息")
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	newRequest := func(sign string) *http.Request {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		form := url.Values{"user_id": {"12345"}}
+		if sign == "" {
+			sign, _ = GenerateSign(map[string]interface{}{
+				"user_id":   "12345",
+				"action":    "login",
+				"timestamp": timestamp,
+				"nonce":     "abc123",
+			}, secretKey)
+		}
+		r := httptest.NewRequest(http.MethodPost, "/api/user?action=login", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		r.Header.Set(HeaderAppID, appID)
+		r.Header.Set(HeaderTimestamp, timestamp)
+		r.Header.Set(HeaderNonce, "abc123")
+		r.Header.Set(HeaderSign, sign)
+		return r
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, newRequest(""))
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("期望验签通过, 实际: %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, newRequest("wrong_sign"))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("期望状态码 %d, 实际 %d", http.StatusUnauthorized, rec.Code)
+	}
+}
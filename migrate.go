@@ -0,0 +1,146 @@
+package go_signature_sdk
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationLockKey 执行迁移时使用的咨询锁，保证多个实例同时启动时只有一个执行迁移
+const migrationLockKey int64 = 0x5349474e53444b
+
+// Migration 数据库迁移
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// Migrations 返回内置的数据库迁移，按版本号升序
+// 文件名格式为 <版本号>_<名称>.sql
+func Migrations() ([]Migration, error) {
+	entries, err := migrationFiles.ReadDir("migrations")
+	if err != nil {
+		return nil, fmt.Errorf("读取迁移文件失败: %w", err)
+	}
+
+	migrations := make([]Migration, 0, len(entries))
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), ".sql")
+		prefix, rest, ok := strings.Cut(name, "_")
+		version, err := strconv.Atoi(prefix)
+		if !ok || err != nil {
+			return nil, fmt.Errorf("迁移文件名无效: %s", entry.Name())
+		}
+
+		content, err := migrationFiles.ReadFile(path.Join("migrations", entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("读取迁移文件失败: %w", err)
+		}
+		migrations = append(migrations, Migration{Version: version, Name: rest, SQL: string(content)})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].Version == migrations[i-1].Version {
+			return nil, fmt.Errorf("迁移版本重复: %d", migrations[i].Version)
+		}
+	}
+	return migrations, nil
+}
+
+// Migrate 执行未应用的数据库迁移，已应用的版本记录在 schema_migrations 表中
+// 每个迁移在独立事务中执行，失败时回滚该迁移并返回错误
+func Migrate(ctx context.Context, db *sql.DB) error {
+	migrations, err := Migrations()
+	if err != nil {
+		return err
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("获取数据库连接失败: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLockKey); err != nil {
+		return fmt.Errorf("获取迁移锁失败: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLockKey)
+
+	query := `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version INTEGER PRIMARY KEY,
+    name VARCHAR(128) NOT NULL,
+    applied_at BIGINT NOT NULL)`
+	if _, err := conn.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("创建schema_migrations表失败: %w", err)
+	}
+
+	applied := make(map[int]bool)
+	rows, err := conn.QueryContext(ctx, `SELECT version FROM schema_migrations`)
+	if err != nil {
+		return fmt.Errorf("查询已应用的迁移失败: %w", err)
+	}
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			rows.Close()
+			return fmt.Errorf("查询已应用的迁移失败: %w", err)
+		}
+		applied[version] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("查询已应用的迁移失败: %w", err)
+	}
+
+	for _, m := range migrations {
+		if applied[m.Version] {
+			continue
+		}
+		if err := applyMigration(ctx, conn, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyMigration 在事务中执行一个迁移
+func applyMigration(ctx context.Context, conn *sql.Conn, m Migration) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("开始迁移事务失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, m.SQL); err != nil {
+		return fmt.Errorf("执行迁移 %04d_%s 失败: %w", m.Version, m.Name, err)
+	}
+	_, err = tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, name, applied_at) VALUES ($1, $2, $3)`,
+		m.Version, m.Name, time.Now().Unix())
+	if err != nil {
+		return fmt.Errorf("记录迁移 %04d_%s 失败: %w", m.Version, m.Name, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("提交迁移 %04d_%s 失败: %w", m.Version, m.Name, err)
+	}
+	return nil
+}
+
+// Migrate 执行未应用的数据库迁移
+func (s *SignatureSDK) Migrate(ctx context.Context) error {
+	if s.db == nil {
+		return fmt.Errorf("未配置数据库连接")
+	}
+	return Migrate(ctx, s.db)
+}
@@ -0,0 +1,47 @@
+-- 应用密钥表
+-- 兼容旧版本 NewSignatureSDK 和 sql/schema.sql 创建的表，缺少的列会被补齐
+CREATE TABLE IF NOT EXISTS app_keys (
+    id SERIAL PRIMARY KEY,
+    app_id VARCHAR(32) NOT NULL UNIQUE,
+    secret_key VARCHAR(64) NOT NULL,
+    ips_white JSONB NOT NULL,
+    status SMALLINT NOT NULL DEFAULT 1,
+    attributes JSONB NOT NULL DEFAULT '{}',
+    sign_type VARCHAR(32) NOT NULL DEFAULT 'MD5',
+    allowed_sign_types JSONB NOT NULL DEFAULT '[]',
+    public_key TEXT NOT NULL DEFAULT '',
+    create_at BIGINT NOT NULL,
+    update_at BIGINT DEFAULT NULL
+);
+
+ALTER TABLE app_keys ADD COLUMN IF NOT EXISTS attributes JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE app_keys ADD COLUMN IF NOT EXISTS sign_type VARCHAR(32) NOT NULL DEFAULT 'MD5';
+ALTER TABLE app_keys ADD COLUMN IF NOT EXISTS allowed_sign_types JSONB NOT NULL DEFAULT '[]';
+ALTER TABLE app_keys ADD COLUMN IF NOT EXISTS public_key TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_app_keys_app_id ON app_keys(app_id);
+CREATE INDEX IF NOT EXISTS idx_app_keys_status ON app_keys(status);
+
+COMMENT ON TABLE app_keys IS '应用密钥表';
+COMMENT ON COLUMN app_keys.app_id IS '应用ID';
+COMMENT ON COLUMN app_keys.secret_key IS '密钥';
+COMMENT ON COLUMN app_keys.ips_white IS 'ip白名单';
+COMMENT ON COLUMN app_keys.status IS '状态 1:启用 0:禁用';
+COMMENT ON COLUMN app_keys.attributes IS '扩展属性';
+COMMENT ON COLUMN app_keys.sign_type IS '生成签名使用的算法';
+COMMENT ON COLUMN app_keys.allowed_sign_types IS '验签额外接受的算法';
+COMMENT ON COLUMN app_keys.public_key IS '非对称算法验签公钥(PEM)';
+COMMENT ON COLUMN app_keys.create_at IS '创建时间戳';
+COMMENT ON COLUMN app_keys.update_at IS '更新时间戳';
+
+-- 变更通知，SDK通过 LISTEN app_keys_changed 清除缓存
+CREATE OR REPLACE FUNCTION notify_app_keys_changed() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('app_keys_changed', COALESCE(NEW.app_id, OLD.app_id));
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_app_keys_changed ON app_keys;
+CREATE TRIGGER trg_app_keys_changed AFTER INSERT OR UPDATE OR DELETE ON app_keys
+    FOR EACH ROW EXECUTE FUNCTION notify_app_keys_changed();
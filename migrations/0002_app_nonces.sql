@@ -0,0 +1,12 @@
+-- 已使用的nonce，由 PostgresNonceStore 使用
+CREATE TABLE IF NOT EXISTS app_nonces (
+    app_id VARCHAR(32) NOT NULL,
+    nonce VARCHAR(64) NOT NULL,
+    expire_at BIGINT NOT NULL,
+    PRIMARY KEY (app_id, nonce)
+);
+
+CREATE INDEX IF NOT EXISTS idx_app_nonces_expire_at ON app_nonces(expire_at);
+
+COMMENT ON TABLE app_nonces IS '已使用的nonce';
+COMMENT ON COLUMN app_nonces.expire_at IS '过期时间戳';
@@ -0,0 +1,32 @@
+-- 应用的多个对称密钥，主密钥同步保存在 app_keys.secret_key
+CREATE TABLE IF NOT EXISTS app_secrets (
+    id SERIAL PRIMARY KEY,
+    app_id VARCHAR(32) NOT NULL REFERENCES app_keys(app_id) ON UPDATE CASCADE ON DELETE CASCADE,
+    key_id VARCHAR(32) NOT NULL,
+    secret VARCHAR(64) NOT NULL,
+    is_primary BOOLEAN NOT NULL DEFAULT FALSE,
+    not_before BIGINT NOT NULL DEFAULT 0,
+    not_after BIGINT NOT NULL DEFAULT 0,
+    create_at BIGINT NOT NULL,
+    UNIQUE (app_id, key_id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_app_secrets_primary ON app_secrets(app_id) WHERE is_primary;
+
+COMMENT ON TABLE app_secrets IS '应用密钥';
+COMMENT ON COLUMN app_secrets.key_id IS '密钥ID';
+COMMENT ON COLUMN app_secrets.secret IS '密钥';
+COMMENT ON COLUMN app_secrets.is_primary IS '是否为生成签名使用的主密钥';
+COMMENT ON COLUMN app_secrets.not_before IS '生效时间戳 0:立即生效';
+COMMENT ON COLUMN app_secrets.not_after IS '失效时间戳 0:永不失效';
+COMMENT ON COLUMN app_secrets.create_at IS '创建时间戳';
+
+-- 已有应用的密钥作为主密钥，密钥ID随机生成，不能由密钥推导
+INSERT INTO app_secrets (app_id, key_id, secret, is_primary, create_at)
+SELECT app_id, 'k' || substr(md5(random()::text || clock_timestamp()::text), 1, 16), secret_key, TRUE, create_at
+FROM app_keys
+WHERE NOT EXISTS (SELECT 1 FROM app_secrets s WHERE s.app_id = app_keys.app_id);
+
+DROP TRIGGER IF EXISTS trg_app_secrets_changed ON app_secrets;
+CREATE TRIGGER trg_app_secrets_changed AFTER INSERT OR UPDATE OR DELETE ON app_secrets
+    FOR EACH ROW EXECUTE FUNCTION notify_app_keys_changed();
@@ -0,0 +1,10 @@
+-- 密钥加密存储，密文比原来的密钥长
+ALTER TABLE app_keys ALTER COLUMN secret_key TYPE TEXT;
+ALTER TABLE app_keys ADD COLUMN IF NOT EXISTS key_version VARCHAR(32) NOT NULL DEFAULT '';
+ALTER TABLE app_secrets ALTER COLUMN secret TYPE TEXT;
+ALTER TABLE app_secrets ADD COLUMN IF NOT EXISTS key_version VARCHAR(32) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN app_keys.secret_key IS '主密钥，key_version 非空时为加密后的密文';
+COMMENT ON COLUMN app_keys.key_version IS '加密数据密钥的主密钥版本，空表示明文';
+COMMENT ON COLUMN app_secrets.secret IS '密钥，key_version 非空时为加密后的密文';
+COMMENT ON COLUMN app_secrets.key_version IS '加密数据密钥的主密钥版本，空表示明文';
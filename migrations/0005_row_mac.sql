@@ -0,0 +1,4 @@
+-- 应用安全相关字段的HMAC，用于发现绕过SDK对数据库的直接修改
+ALTER TABLE app_keys ADD COLUMN IF NOT EXISTS row_mac VARCHAR(64) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN app_keys.row_mac IS '密钥、IP白名单、状态、签名算法和app_secrets的HMAC-SHA256';
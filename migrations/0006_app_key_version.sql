@@ -0,0 +1,4 @@
+-- 乐观锁版本号，每次修改应用时加1
+ALTER TABLE app_keys ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+
+COMMENT ON COLUMN app_keys.version IS '版本号，每次修改加1，用于乐观并发控制';
@@ -0,0 +1,9 @@
+-- 应用状态变更的原因和操作人
+ALTER TABLE app_keys ADD COLUMN IF NOT EXISTS status_reason TEXT NOT NULL DEFAULT '';
+ALTER TABLE app_keys ADD COLUMN IF NOT EXISTS status_actor VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE app_keys ADD COLUMN IF NOT EXISTS status_changed_at BIGINT NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN app_keys.status IS '状态 0:暂停 1:正常 2:待审核 3:已吊销 4:已删除';
+COMMENT ON COLUMN app_keys.status_reason IS '最近一次状态变更的原因';
+COMMENT ON COLUMN app_keys.status_actor IS '最近一次状态变更的操作人';
+COMMENT ON COLUMN app_keys.status_changed_at IS '最近一次状态变更的时间戳';
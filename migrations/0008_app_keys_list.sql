@@ -0,0 +1,28 @@
+-- ListAppKeys 使用的索引和函数
+
+CREATE INDEX IF NOT EXISTS idx_app_keys_create_at ON app_keys(create_at, id);
+CREATE INDEX IF NOT EXISTS idx_app_keys_attributes ON app_keys USING GIN (attributes jsonb_path_ops);
+
+-- 白名单是否包含IP：条目与IP相同，或条目为包含该IP的CIDR，与SDK中的白名单匹配规则一致
+-- 无效的CIDR条目被忽略
+CREATE OR REPLACE FUNCTION app_keys_whitelist_contains(ips_white JSONB, ip TEXT) RETURNS BOOLEAN AS $$
+DECLARE
+    entry TEXT;
+BEGIN
+    FOR entry IN SELECT jsonb_array_elements_text(ips_white) LOOP
+        IF entry = ip THEN
+            RETURN TRUE;
+        END IF;
+        IF position('/' IN entry) > 0 THEN
+            BEGIN
+                IF ip::inet <<= entry::inet THEN
+                    RETURN TRUE;
+                END IF;
+            EXCEPTION WHEN others THEN
+                NULL;
+            END;
+        END IF;
+    END LOOP;
+    RETURN FALSE;
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
@@ -0,0 +1,17 @@
+-- 软删除和清除
+ALTER TABLE app_keys ADD COLUMN IF NOT EXISTS deleted_at BIGINT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_app_keys_deleted_at ON app_keys(deleted_at) WHERE status = 4;
+
+COMMENT ON COLUMN app_keys.deleted_at IS '删除时间戳，0表示未删除';
+
+-- 已清除的应用ID，防止被重新创建
+CREATE TABLE IF NOT EXISTS app_tombstones (
+    app_id VARCHAR(32) PRIMARY KEY,
+    deleted_at BIGINT NOT NULL,
+    purged_at BIGINT NOT NULL
+);
+
+COMMENT ON TABLE app_tombstones IS '已清除的应用ID';
+COMMENT ON COLUMN app_tombstones.deleted_at IS '删除时间戳';
+COMMENT ON COLUMN app_tombstones.purged_at IS '清除时间戳';
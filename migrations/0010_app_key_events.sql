@@ -0,0 +1,36 @@
+-- 应用变更的审计记录，只允许追加
+CREATE TABLE IF NOT EXISTS app_key_events (
+    id BIGSERIAL PRIMARY KEY,
+    app_id VARCHAR(32) NOT NULL,
+    action VARCHAR(32) NOT NULL,
+    actor VARCHAR(64) NOT NULL DEFAULT '',
+    create_at BIGINT NOT NULL,
+    changes JSONB NOT NULL DEFAULT '{}',
+    snapshot JSONB
+);
+
+CREATE INDEX IF NOT EXISTS idx_app_key_events_app_id ON app_key_events(app_id, create_at, id);
+
+COMMENT ON TABLE app_key_events IS '应用变更的审计记录';
+COMMENT ON COLUMN app_key_events.action IS '操作类型，如 create、update、status';
+COMMENT ON COLUMN app_key_events.actor IS '操作人';
+COMMENT ON COLUMN app_key_events.create_at IS '变更时间戳';
+COMMENT ON COLUMN app_key_events.changes IS '变更的字段 {"字段": {"before": 旧值, "after": 新值}}，密钥已脱敏';
+COMMENT ON COLUMN app_key_events.snapshot IS '变更后的应用，密钥已脱敏；清除时为空';
+
+-- 禁止修改和删除审计记录
+CREATE OR REPLACE FUNCTION app_key_events_append_only() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'app_key_events 只允许追加';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS app_key_events_append_only ON app_key_events;
+CREATE TRIGGER app_key_events_append_only
+    BEFORE UPDATE OR DELETE ON app_key_events
+    FOR EACH ROW EXECUTE FUNCTION app_key_events_append_only();
+
+DROP TRIGGER IF EXISTS app_key_events_no_truncate ON app_key_events;
+CREATE TRIGGER app_key_events_no_truncate
+    BEFORE TRUNCATE ON app_key_events
+    FOR EACH STATEMENT EXECUTE FUNCTION app_key_events_append_only();
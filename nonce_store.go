@@ -0,0 +1,160 @@
+package go_signature_sdk
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// NonceStore nonce存储，用于防止请求重放
+type NonceStore interface {
+	// Use 登记nonce，ttl 内同一应用的nonce再次出现时返回 ErrReplayedRequest
+	Use(ctx context.Context, appID, nonce string, ttl time.Duration) error
+}
+
+// MemoryNonceStore 进程内nonce存储，适用于单实例部署
+type MemoryNonceStore struct {
+	// Clock 时钟，为空时使用 time.Now
+	Clock func() time.Time
+
+	mu        sync.Mutex
+	nonces    map[string]time.Time
+	lastSweep time.Time
+}
+
+// memoryNonceSweepInterval 过期nonce清理间隔
+const memoryNonceSweepInterval = time.Minute
+
+// NewMemoryNonceStore 创建进程内nonce存储
+func NewMemoryNonceStore() *MemoryNonceStore {
+	return &MemoryNonceStore{nonces: make(map[string]time.Time)}
+}
+
+func (m *MemoryNonceStore) now() time.Time {
+	if m.Clock != nil {
+		return m.Clock()
+	}
+	return time.Now()
+}
+
+// Use 登记nonce
+func (m *MemoryNonceStore) Use(_ context.Context, appID, nonce string, ttl time.Duration) error {
+	now := m.now()
+	key := appID + "\x00" + nonce
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if now.Sub(m.lastSweep) >= memoryNonceSweepInterval {
+		m.sweep(now)
+	}
+
+	if expireAt, ok := m.nonces[key]; ok && now.Before(expireAt) {
+		return ErrReplayedRequest
+	}
+	m.nonces[key] = now.Add(ttl)
+	return nil
+}
+
+// Len 返回当前记录的nonce数量
+func (m *MemoryNonceStore) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.nonces)
+}
+
+// sweep 清理过期nonce，调用方需持有锁
+func (m *MemoryNonceStore) sweep(now time.Time) {
+	for key, expireAt := range m.nonces {
+		if !now.Before(expireAt) {
+			delete(m.nonces, key)
+		}
+	}
+	m.lastSweep = now
+}
+
+// PostgresNonceStore 基于PostgreSQL的nonce存储，适用于多实例部署
+type PostgresNonceStore struct {
+	// Clock 时钟，为空时使用 time.Now
+	Clock func() time.Time
+	// QueryTimeout 每次查询的超时时间，ctx 的截止时间更早时以 ctx 为准；为0时只受 ctx 控制
+	QueryTimeout time.Duration
+
+	db *sql.DB
+}
+
+// NewPostgresNonceStore 创建PostgreSQL nonce存储
+// 表结构由 Migrate 创建
+func NewPostgresNonceStore(db *sql.DB) *PostgresNonceStore {
+	return &PostgresNonceStore{db: db}
+}
+
+func (p *PostgresNonceStore) now() time.Time {
+	if p.Clock != nil {
+		return p.Clock()
+	}
+	return time.Now()
+}
+
+// Use 登记nonce，已过期但未清理的记录可以被重新登记
+func (p *PostgresNonceStore) Use(ctx context.Context, appID, nonce string, ttl time.Duration) error {
+	ctx, cancel := withQueryTimeout(ctx, p.QueryTimeout)
+	defer cancel()
+
+	now := p.now()
+	query := `
+		INSERT INTO app_nonces (app_id, nonce, expire_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (app_id, nonce) DO UPDATE SET expire_at = EXCLUDED.expire_at
+		WHERE app_nonces.expire_at <= $4
+	`
+	result, err := p.db.ExecContext(ctx, query, appID, nonce, now.Add(ttl).Unix(), now.Unix())
+	if err != nil {
+		return fmt.Errorf("登记nonce失败: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("获取更新行数失败: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrReplayedRequest
+	}
+	return nil
+}
+
+// Cleanup 删除过期的nonce，返回删除的行数
+func (p *PostgresNonceStore) Cleanup(ctx context.Context) (int64, error) {
+	ctx, cancel := withQueryTimeout(ctx, p.QueryTimeout)
+	defer cancel()
+
+	result, err := p.db.ExecContext(ctx, `DELETE FROM app_nonces WHERE expire_at <= $1`, p.now().Unix())
+	if err != nil {
+		return 0, fmt.Errorf("清理nonce失败: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// StartCleanup 启动后台定时清理，返回停止函数
+func (p *PostgresNonceStore) StartCleanup(interval time.Duration) (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := p.Cleanup(ctx); err != nil && ctx.Err() == nil {
+					log.Println("清理过期nonce失败:", err)
+				}
+			}
+		}
+	}()
+	return cancel
+}
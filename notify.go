@@ -0,0 +1,104 @@
+package go_signature_sdk
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// AppKeysNotifyChannel app_keys 变更通知的频道，通知内容为 app_id
+const AppKeysNotifyChannel = "app_keys_changed"
+
+// 监听连接的重连间隔和保活间隔
+const (
+	listenerMinReconnectInterval = 100 * time.Millisecond
+	listenerMaxReconnectInterval = 10 * time.Second
+	listenerPingInterval         = 30 * time.Second
+)
+
+// invalidationListener 通过 LISTEN/NOTIFY 接收其他实例的应用变更并清除本地缓存
+type invalidationListener struct {
+	listener *pq.Listener
+	cache    *cachedKeyStore
+	stop     chan struct{}
+	done     chan struct{}
+	once     sync.Once
+}
+
+// newInvalidationListener 连接数据库并开始监听变更
+// 连接断开期间无法收到通知，因此断开和重连时都会清空缓存，重连后从数据库重新加载
+func newInvalidationListener(dsn string, cache *cachedKeyStore) (*invalidationListener, error) {
+	l := &invalidationListener{
+		cache: cache,
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+
+	l.listener = pq.NewListener(dsn, listenerMinReconnectInterval, listenerMaxReconnectInterval, l.onEvent)
+	if err := l.listener.Listen(AppKeysNotifyChannel); err != nil {
+		l.listener.Close()
+		return nil, fmt.Errorf("监听应用变更失败: %w", err)
+	}
+
+	go l.run()
+	return l, nil
+}
+
+// onEvent 处理连接状态变化
+func (l *invalidationListener) onEvent(event pq.ListenerEventType, err error) {
+	switch event {
+	case pq.ListenerEventDisconnected:
+		log.Println("应用变更监听连接断开:", err)
+		l.cache.Purge()
+	case pq.ListenerEventReconnected:
+		l.cache.Purge()
+	case pq.ListenerEventConnectionAttemptFailed:
+		log.Println("应用变更监听重连失败:", err)
+	}
+}
+
+func (l *invalidationListener) run() {
+	defer close(l.done)
+
+	ticker := time.NewTicker(listenerPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.stop:
+			return
+		case n := <-l.listener.Notify:
+			// 重连后会收到 nil，此时可能遗漏了变更
+			if n == nil {
+				l.cache.Purge()
+				continue
+			}
+			l.cache.Invalidate(n.Extra)
+		case <-ticker.C:
+			// 保活并尽快发现断开的连接
+			go l.listener.Ping()
+		}
+	}
+}
+
+// Close 停止监听
+func (l *invalidationListener) Close() error {
+	var err error
+	l.once.Do(func() {
+		close(l.stop)
+		<-l.done
+		err = l.listener.Close()
+	})
+	return err
+}
+
+// Close 释放SDK持有的后台资源
+func (s *SignatureSDK) Close() error {
+	if s.listener != nil {
+		return s.listener.Close()
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package go_signature_sdk
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// AppKeyPatch 应用的部分更新，为 nil 的字段保持不变
+type AppKeyPatch struct {
+	SecretKey *string    // 替换当前的主密钥
+	IPsWhite  []string   // 替换IP白名单，传入空切片表示清空（即不限制IP）
+	Status    *AppStatus // 状态，需符合状态变更规则
+	// StatusReason 状态变更的原因，操作人取自 ctx（见 WithActor）；Status 为 nil 或状态不变时忽略
+	StatusReason string
+	Attributes   map[string]interface{} // 替换Attributes，传入空 map 表示清空
+	// Version 期望的当前版本号，非0时与存储中的版本不一致则返回 ErrVersionConflict
+	Version int64
+}
+
+// PatchAppKey 只修改 patch 中指定的字段，返回修改后的版本号
+// 先读取应用得到 Version，修改时带上该版本，可以避免覆盖其他调用方的并发修改
+func (s *SignatureSDK) PatchAppKey(ctx context.Context, appID string, patch AppKeyPatch) (int64, error) {
+	if patch.SecretKey != nil {
+		if err := s.policy.ValidateSecret(*patch.SecretKey); err != nil {
+			return 0, err
+		}
+	}
+	if patch.IPsWhite != nil {
+		if err := validateWhitelist(patch.IPsWhite); err != nil {
+			return 0, err
+		}
+	}
+	return s.store.PatchAppKey(ctx, appID, &patch)
+}
+
+// AddWhitelistIPs 向IP白名单追加IP或CIDR，已存在的条目会被忽略
+func (s *SignatureSDK) AddWhitelistIPs(ctx context.Context, appID string, ips ...string) error {
+	if err := validateWhitelist(ips); err != nil {
+		return err
+	}
+	return s.store.AddWhitelistIPs(ctx, appID, uniqueStrings(ips))
+}
+
+// RemoveWhitelistIPs 从IP白名单删除IP或CIDR，不存在的条目会被忽略
+// 白名单为空时不限制IP，删除后白名单为空时返回 ErrEmptyWhitelist 且不做修改
+func (s *SignatureSDK) RemoveWhitelistIPs(ctx context.Context, appID string, ips ...string) error {
+	return s.store.RemoveWhitelistIPs(ctx, appID, uniqueStrings(ips))
+}
+
+// ClearWhitelist 清空IP白名单，清空后允许任意IP访问
+func (s *SignatureSDK) ClearWhitelist(ctx context.Context, appID string) error {
+	_, err := s.store.PatchAppKey(ctx, appID, &AppKeyPatch{IPsWhite: []string{}})
+	return err
+}
+
+// MergeAttributes 将 attributes 浅合并到应用的Attributes中，值为 nil 的键会被删除
+func (s *SignatureSDK) MergeAttributes(ctx context.Context, appID string, attributes map[string]interface{}) error {
+	return s.store.MergeAttributes(ctx, appID, attributes)
+}
+
+// validateWhitelist 校验白名单条目为单个IP或CIDR
+func validateWhitelist(ips []string) error {
+	for _, ip := range ips {
+		valid := net.ParseIP(ip) != nil
+		if strings.Contains(ip, "/") {
+			_, _, err := net.ParseCIDR(ip)
+			valid = err == nil
+		}
+		if !valid {
+			return fmt.Errorf("%w: 无效的IP或CIDR %q", ErrInvalidRequest, ip)
+		}
+	}
+	return nil
+}
+
+// uniqueStrings 去重并保持顺序
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// mergeAttributes 浅合并，值为 nil 的键会被删除
+func mergeAttributes(dst, src map[string]interface{}) map[string]interface{} {
+	if dst == nil {
+		dst = make(map[string]interface{}, len(src))
+	}
+	for k, v := range src {
+		if v == nil {
+			delete(dst, k)
+		} else {
+			dst[k] = cloneValue(v)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,141 @@
+package go_signature_sdk
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// TestPatchAppKey 测试内存存储的部分更新
+func TestPatchAppKey(t *testing.T) {
+	testPatchAppKey(t, createMemorySDK(t))
+}
+
+// TestSDKPatchAppKey 测试PostgreSQL存储的部分更新
+func TestSDKPatchAppKey(t *testing.T) {
+	sdk, db := createTestSDK(t)
+	defer teardownTestDB(t, db)
+	testPatchAppKey(t, sdk)
+}
+
+func testPatchAppKey(t *testing.T, sdk *SignatureSDK) {
+	ctx := context.Background()
+	appID := "test_app_patch"
+	attributes := map[string]interface{}{"tenant": "a", "tier": "gold"}
+	if err := sdk.CreateAppKey(appID, "test_secret", []string{"127.0.0.1"}, attributes); err != nil {
+		t.Fatalf("创建测试应用失败: %v", err)
+	}
+	appKey, err := sdk.GetAppKey(appID)
+	if err != nil {
+		t.Fatalf("获取应用失败: %v", err)
+	}
+	if appKey.Version != 1 {
+		t.Errorf("期望初始版本为1, 实际: %d", appKey.Version)
+	}
+
+	// 只修改状态，其他字段不变，原因来自 patch，操作人来自 ctx
+	status := StatusSuspended
+	patch := AppKeyPatch{Status: &status, StatusReason: "欠费", Version: appKey.Version}
+	version, err := sdk.PatchAppKey(WithActor(ctx, "alice"), appID, patch)
+	if err != nil {
+		t.Fatalf("修改状态失败: %v", err)
+	}
+	patched, _ := sdk.GetAppKey(appID)
+	if version != 2 || patched.Version != 2 || patched.Status != 0 || patched.SecretKey != "test_secret" ||
+		!reflect.DeepEqual(patched.IPsWhite, []string{"127.0.0.1"}) || patched.Attributes["tenant"] != "a" {
+		t.Errorf("部分更新错误: version=%d, %+v", version, patched)
+	}
+	if patched.StatusReason != "欠费" || patched.StatusActor != "alice" {
+		t.Errorf("状态变更记录错误: reason=%q, actor=%q", patched.StatusReason, patched.StatusActor)
+	}
+
+	// 使用旧版本修改失败
+	secret := "new_secret"
+	if _, err := sdk.PatchAppKey(ctx, appID, AppKeyPatch{SecretKey: &secret, Version: appKey.Version}); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("期望版本冲突错误, 实际: %v", err)
+	}
+	if _, err := sdk.PatchAppKey(ctx, appID, AppKeyPatch{SecretKey: &secret, IPsWhite: []string{}}); err != nil {
+		t.Fatalf("修改密钥失败: %v", err)
+	}
+	patched, _ = sdk.GetAppKey(appID)
+	if patched.SecretKey != "new_secret" || patched.primarySecret().Secret != "new_secret" || len(patched.IPsWhite) != 0 {
+		t.Errorf("修改密钥和白名单错误: %+v", patched)
+	}
+	if _, err := sdk.PatchAppKey(ctx, appID, AppKeyPatch{IPsWhite: []string{"not_an_ip"}}); !errors.Is(err, ErrInvalidRequest) {
+		t.Errorf("期望无效IP错误, 实际: %v", err)
+	}
+	if _, err := sdk.PatchAppKey(ctx, "not_exist", AppKeyPatch{Status: &status}); !errors.Is(err, ErrAppNotFound) {
+		t.Errorf("期望应用不存在错误, 实际: %v", err)
+	}
+
+	// 并发追加白名单不会丢失更新
+	var wg sync.WaitGroup
+	for _, ip := range []string{"10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.0/24"} {
+		wg.Add(1)
+		go func(ip string) {
+			defer wg.Done()
+			if err := sdk.AddWhitelistIPs(ctx, appID, ip, ip); err != nil {
+				t.Errorf("追加白名单失败: %v", err)
+			}
+		}(ip)
+	}
+	wg.Wait()
+	if err := sdk.AddWhitelistIPs(ctx, appID, "10.0.0.1", "192.168.1.1"); err != nil {
+		t.Fatalf("追加白名单失败: %v", err)
+	}
+	patched, _ = sdk.GetAppKey(appID)
+	if len(patched.IPsWhite) != 5 || patched.IPsWhite[4] != "192.168.1.1" {
+		t.Errorf("追加白名单错误: %v", patched.IPsWhite)
+	}
+	if err := sdk.AddWhitelistIPs(ctx, appID, "10.0.0.256"); !errors.Is(err, ErrInvalidRequest) {
+		t.Errorf("期望无效IP错误, 实际: %v", err)
+	}
+
+	if err := sdk.RemoveWhitelistIPs(ctx, appID, "10.0.0.2", "172.16.0.1"); err != nil {
+		t.Fatalf("删除白名单失败: %v", err)
+	}
+	patched, _ = sdk.GetAppKey(appID)
+	for _, ip := range patched.IPsWhite {
+		if ip == "10.0.0.2" {
+			t.Errorf("白名单条目未删除: %v", patched.IPsWhite)
+		}
+	}
+	if len(patched.IPsWhite) != 4 {
+		t.Errorf("删除白名单错误: %v", patched.IPsWhite)
+	}
+
+	// 删除全部条目会允许任意IP访问，需显式清空
+	if err := sdk.RemoveWhitelistIPs(ctx, appID, patched.IPsWhite...); !errors.Is(err, ErrEmptyWhitelist) {
+		t.Errorf("期望白名单为空错误, 实际: %v", err)
+	}
+	if unchanged, _ := sdk.GetAppKey(appID); len(unchanged.IPsWhite) != 4 || unchanged.Version != patched.Version {
+		t.Errorf("删除失败时不应修改白名单: %+v", unchanged)
+	}
+	if err := sdk.ClearWhitelist(ctx, appID); err != nil {
+		t.Fatalf("清空白名单失败: %v", err)
+	}
+	patched, _ = sdk.GetAppKey(appID)
+	if len(patched.IPsWhite) != 0 {
+		t.Errorf("清空白名单错误: %v", patched.IPsWhite)
+	}
+
+	if err := sdk.MergeAttributes(ctx, appID, map[string]interface{}{"tier": nil, "region": "cn"}); err != nil {
+		t.Fatalf("合并Attributes失败: %v", err)
+	}
+	patched, _ = sdk.GetAppKey(appID)
+	expected := map[string]interface{}{"tenant": "a", "region": "cn"}
+	if !reflect.DeepEqual(patched.Attributes, expected) {
+		t.Errorf("合并Attributes错误: %v", patched.Attributes)
+	}
+
+	if err := sdk.SetStatus(ctx, appID, StatusChange{Status: StatusActive}); err != nil {
+		t.Fatalf("设置状态失败: %v", err)
+	}
+	before := patched.Version
+	patched, _ = sdk.GetAppKey(appID)
+	if patched.Status != 1 || patched.Version != before+1 {
+		t.Errorf("设置状态错误: status=%d, version=%d", patched.Status, patched.Version)
+	}
+}
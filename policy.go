@@ -0,0 +1,174 @@
+package go_signature_sdk
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxAppIDLength app_keys.app_id 列的长度上限
+const maxAppIDLength = 32
+
+// 校验失败的规则
+const (
+	PolicyRuleLength   = "length"   // 长度不符合要求
+	PolicyRuleEntropy  = "entropy"  // 估计熵过低
+	PolicyRuleAlphabet = "alphabet" // 含有不允许的字符
+	PolicyRuleDenylist = "denylist" // 常见弱密钥
+	PolicyRulePattern  = "pattern"  // 应用ID不匹配格式
+)
+
+// commonSecrets 常见的弱密钥，比较时忽略大小写
+var commonSecrets = []string{
+	"secret", "secret_key", "secretkey", "my_secret", "my_secret_key", "mysecret",
+	"test", "test_secret", "testsecret", "demo", "example", "default", "changeme",
+	"password", "passw0rd", "password1", "p@ssw0rd", "admin", "root", "qwerty",
+	"123456", "12345678", "123456789", "1234567890", "000000", "111111",
+	"abc123", "letmein", "welcome", "iloveyou",
+}
+
+// CredentialPolicy 应用ID和密钥的校验规则
+// CreateAppKey、UpdateAppKey、AddSecret、RotateSecret 和 IssueCredentials 写入前都会校验；
+// 为 nil 时只要求密钥非空、应用ID为1到32个字符
+type CredentialPolicy struct {
+	// MinSecretLength 密钥的最小长度（字符数），小于1时按1处理
+	MinSecretLength int
+	// MinEntropyBits 密钥的最小估计熵（位），为0时不检查
+	MinEntropyBits float64
+	// SecretAlphabet 密钥允许的字符，为空时不限制
+	SecretAlphabet string
+	// DeniedSecrets 禁止使用的密钥，比较时忽略大小写
+	DeniedSecrets []string
+	// AppIDPattern 应用ID的格式，为空时不限制；无论格式如何，应用ID都不能超过32个字符
+	AppIDPattern *regexp.Regexp
+}
+
+// DefaultCredentialPolicy 返回推荐的校验规则
+// 密钥至少16个字符、估计熵不低于48位且不在常见弱密钥中，应用ID只能包含字母、数字、下划线、点和连字符
+func DefaultCredentialPolicy() *CredentialPolicy {
+	return &CredentialPolicy{
+		MinSecretLength: 16,
+		MinEntropyBits:  48,
+		DeniedSecrets:   append([]string(nil), commonSecrets...),
+		AppIDPattern:    regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`),
+	}
+}
+
+// CredentialPolicyError 应用ID或密钥不符合校验规则
+// 可以用 errors.Is 判断 ErrInvalidAppID / ErrWeakSecret，用 errors.As 获取失败的字段和规则
+type CredentialPolicyError struct {
+	Field  string // app_id 或 secret
+	Rule   string // PolicyRule* 之一
+	Reason string
+}
+
+func (e *CredentialPolicyError) Error() string {
+	return fmt.Sprintf("%v: %s", e.Unwrap(), e.Reason)
+}
+
+// Unwrap 应用ID返回 ErrInvalidAppID，密钥返回 ErrWeakSecret
+func (e *CredentialPolicyError) Unwrap() error {
+	if e.Field == "app_id" {
+		return ErrInvalidAppID
+	}
+	return ErrWeakSecret
+}
+
+// ValidateAppID 校验应用ID，p 为 nil 时只检查长度
+func (p *CredentialPolicy) ValidateAppID(appID string) error {
+	if n := utf8.RuneCountInString(appID); n == 0 || n > maxAppIDLength {
+		return &CredentialPolicyError{Field: "app_id", Rule: PolicyRuleLength,
+			Reason: fmt.Sprintf("长度必须为1到%d个字符", maxAppIDLength)}
+	}
+	if p != nil && p.AppIDPattern != nil && !p.AppIDPattern.MatchString(appID) {
+		return &CredentialPolicyError{Field: "app_id", Rule: PolicyRulePattern,
+			Reason: fmt.Sprintf("不匹配格式 %s", p.AppIDPattern)}
+	}
+	return nil
+}
+
+// ValidateSecret 校验密钥强度，p 为 nil 时只要求非空；错误信息不包含密钥内容
+func (p *CredentialPolicy) ValidateSecret(secret string) error {
+	minLength := 1
+	if p != nil && p.MinSecretLength > minLength {
+		minLength = p.MinSecretLength
+	}
+	if utf8.RuneCountInString(secret) < minLength {
+		return &CredentialPolicyError{Field: "secret", Rule: PolicyRuleLength,
+			Reason: fmt.Sprintf("长度不能少于%d个字符", minLength)}
+	}
+	if p == nil {
+		return nil
+	}
+
+	if p.SecretAlphabet != "" {
+		for _, r := range secret {
+			if !strings.ContainsRune(p.SecretAlphabet, r) {
+				return &CredentialPolicyError{Field: "secret", Rule: PolicyRuleAlphabet,
+					Reason: "含有不允许的字符"}
+			}
+		}
+	}
+	for _, denied := range p.DeniedSecrets {
+		if strings.EqualFold(secret, denied) {
+			return &CredentialPolicyError{Field: "secret", Rule: PolicyRuleDenylist,
+				Reason: "是常见的弱密钥"}
+		}
+	}
+	if p.MinEntropyBits > 0 {
+		if bits := estimateEntropy(secret); bits < p.MinEntropyBits {
+			return &CredentialPolicyError{Field: "secret", Rule: PolicyRuleEntropy,
+				Reason: fmt.Sprintf("估计熵 %.0f 位，低于 %.0f 位", bits, p.MinEntropyBits)}
+		}
+	}
+	return nil
+}
+
+// estimateEntropy 粗略估计字符串的熵（位）
+// 取字符集估计（长度 × log2(出现的字符类别的大小)）和香农熵估计中的较小值，
+// 前者会高估重复字符组成的串，后者对短的随机串偏低，取较小值偏保守
+func estimateEntropy(s string) float64 {
+	var lower, upper, digit, other bool
+	counts := make(map[rune]int)
+	n := 0
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			other = true
+		}
+		counts[r]++
+		n++
+	}
+	if n == 0 {
+		return 0
+	}
+
+	pool := 0
+	for _, class := range []struct {
+		present bool
+		size    int
+	}{{lower, 26}, {upper, 26}, {digit, 10}, {other, 33}} {
+		if class.present {
+			pool += class.size
+		}
+	}
+	poolBits := float64(n) * math.Log2(float64(pool))
+
+	var shannon float64
+	for _, c := range counts {
+		p := float64(c) / float64(n)
+		shannon -= p * math.Log2(p)
+	}
+	shannonBits := shannon * float64(n)
+
+	return math.Min(poolBits, shannonBits)
+}
@@ -0,0 +1,143 @@
+package go_signature_sdk
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestCredentialPolicy 测试应用ID和密钥的校验规则
+func TestCredentialPolicy(t *testing.T) {
+	policy := DefaultCredentialPolicy()
+	valid, err := generateCredential(secretPrefix, CredentialLive, secretRandomLen)
+	if err != nil {
+		t.Fatalf("生成密钥失败: %v", err)
+	}
+
+	secrets := []struct {
+		secret string
+		rule   string
+	}{
+		{valid, ""},
+		{"9fK2mQx7LpR4vTz8", ""},
+		{"", PolicyRuleLength},
+		{"abc", PolicyRuleLength},
+		{"aaaaaaaaaaaaaaaaaaaaaaaa", PolicyRuleEntropy},
+		{"abababababababababababab", PolicyRuleEntropy},
+		{"PASSWORD", PolicyRuleLength},
+		{"1234567890", PolicyRuleLength},
+	}
+	for _, tc := range secrets {
+		err := policy.ValidateSecret(tc.secret)
+		if tc.rule == "" {
+			if err != nil {
+				t.Errorf("期望密钥有效: %q, %v", tc.secret, err)
+			}
+			continue
+		}
+		var policyErr *CredentialPolicyError
+		if !errors.As(err, &policyErr) || policyErr.Rule != tc.rule || !errors.Is(err, ErrWeakSecret) {
+			t.Errorf("期望规则 %s 校验失败: %q, %v", tc.rule, tc.secret, err)
+		}
+	}
+
+	policy.MinSecretLength = 1
+	policy.MinEntropyBits = 0
+	if err := policy.ValidateSecret("Password"); !isPolicyRule(err, PolicyRuleDenylist) || strings.Contains(err.Error(), "Password") {
+		t.Errorf("期望弱密钥错误且不包含密钥, 实际: %v", err)
+	}
+	policy.SecretAlphabet = "0123456789abcdef"
+	if err := policy.ValidateSecret("9fK2mQx7"); !isPolicyRule(err, PolicyRuleAlphabet) {
+		t.Errorf("期望字符集错误, 实际: %v", err)
+	}
+	if err := policy.ValidateSecret("9f2e7a"); err != nil {
+		t.Errorf("期望密钥有效: %v", err)
+	}
+
+	appIDs := []struct {
+		appID string
+		rule  string
+	}{
+		{"test_app_001", ""},
+		{"partner.example-1", ""},
+		{"", PolicyRuleLength},
+		{strings.Repeat("a", 33), PolicyRuleLength},
+		{"_app", PolicyRulePattern},
+		{"app id", PolicyRulePattern},
+		{"应用", PolicyRulePattern},
+	}
+	for _, tc := range appIDs {
+		err := policy.ValidateAppID(tc.appID)
+		if tc.rule == "" {
+			if err != nil {
+				t.Errorf("期望应用ID有效: %q, %v", tc.appID, err)
+			}
+			continue
+		}
+		if !isPolicyRule(err, tc.rule) || !errors.Is(err, ErrInvalidAppID) {
+			t.Errorf("期望规则 %s 校验失败: %q, %v", tc.rule, tc.appID, err)
+		}
+	}
+
+	// 未配置时只检查密钥非空和应用ID长度
+	var none *CredentialPolicy
+	if err := none.ValidateSecret("s"); err != nil {
+		t.Errorf("未配置时不应检查强度: %v", err)
+	}
+	if err := none.ValidateSecret(""); !isPolicyRule(err, PolicyRuleLength) {
+		t.Errorf("期望空密钥错误, 实际: %v", err)
+	}
+	if err := none.ValidateAppID(strings.Repeat("a", 33)); !isPolicyRule(err, PolicyRuleLength) {
+		t.Errorf("期望应用ID过长错误, 实际: %v", err)
+	}
+}
+
+// TestSDKCredentialPolicy 测试SDK写入前校验
+func TestSDKCredentialPolicy(t *testing.T) {
+	sdk := NewSignatureSDK(&Config{KeyStore: NewMemoryKeyStore(), CredentialPolicy: DefaultCredentialPolicy()})
+	ctx := context.Background()
+	strong := "9fK2mQx7LpR4vTz8"
+
+	if err := sdk.CreateAppKey("test_app", "my_secret_key", nil, nil); !errors.Is(err, ErrWeakSecret) {
+		t.Errorf("期望弱密钥错误, 实际: %v", err)
+	}
+	if err := sdk.CreateAppKey(strings.Repeat("a", 33), strong, nil, nil); !errors.Is(err, ErrInvalidAppID) {
+		t.Errorf("期望应用ID错误, 实际: %v", err)
+	}
+	if err := sdk.CreateAppKey("test_app", strong, nil, nil); err != nil {
+		t.Fatalf("创建应用失败: %v", err)
+	}
+	if err := sdk.UpdateAppKey("test_app", "abc", nil, 1, nil); !errors.Is(err, ErrWeakSecret) {
+		t.Errorf("期望弱密钥错误, 实际: %v", err)
+	}
+	if err := sdk.AddSecret(ctx, "test_app", &AppSecret{Secret: "changeme"}); !errors.Is(err, ErrWeakSecret) {
+		t.Errorf("期望弱密钥错误, 实际: %v", err)
+	}
+	if appKey, _ := sdk.GetAppKey("test_app"); appKey.SecretKey != strong || len(appKey.Secrets) != 1 {
+		t.Errorf("校验失败时不应写入: %+v", appKey)
+	}
+
+	if _, err := sdk.RotateSecret(ctx, "test_app", 0); err != nil {
+		t.Errorf("轮换密钥失败: %v", err)
+	}
+	if _, err := sdk.IssueCredentials(ctx, IssueOptions{}); err != nil {
+		t.Errorf("签发凭证失败: %v", err)
+	}
+
+	// 轮换生成的密钥同样需要符合规则
+	strict := DefaultCredentialPolicy()
+	strict.SecretAlphabet = "0123456789abcdef"
+	sdk = NewSignatureSDK(&Config{KeyStore: NewMemoryKeyStore(), CredentialPolicy: strict})
+	if err := sdk.CreateAppKey("test_app", "9f2e7a4c1b8d3e6f0a5c", nil, nil); err != nil {
+		t.Fatalf("创建应用失败: %v", err)
+	}
+	if _, err := sdk.RotateSecret(ctx, "test_app", 0); !isPolicyRule(err, PolicyRuleAlphabet) {
+		t.Errorf("期望字符集错误, 实际: %v", err)
+	}
+}
+
+func isPolicyRule(err error, rule string) bool {
+	var policyErr *CredentialPolicyError
+	return errors.As(err, &policyErr) && policyErr.Rule == rule
+}
@@ -0,0 +1,133 @@
+package go_signature_sdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// DefaultTimestampSkew 默认允许的时间误差
+const DefaultTimestampSkew = 5 * time.Minute
+
+// MaxNonceLength nonce的最大长度，与 app_nonces.nonce 的 VARCHAR(64) 一致
+const MaxNonceLength = 64
+
+// now 返回当前时间
+func (s *SignatureSDK) now() time.Time {
+	if s.clock != nil {
+		return s.clock()
+	}
+	return time.Now()
+}
+
+// nonceTTL nonce保留时长，覆盖时间戳可被接受的整个窗口
+func (s *SignatureSDK) nonceTTL() time.Duration {
+	return 2 * s.timestampSkew
+}
+
+// verifyTimestamp 验证时间戳与 now 的误差是否在允许范围内
+func (s *SignatureSDK) verifyTimestamp(timestamp int64, now time.Time) error {
+	diff := now.Sub(time.Unix(timestamp, 0))
+	if diff > s.timestampSkew || diff < -s.timestampSkew {
+		return ErrExpiredRequest
+	}
+	return nil
+}
+
+// verifyReplay 检查请求的时间戳和nonce，需在签名验证通过后调用以免伪造请求占用nonce
+func (s *SignatureSDK) verifyReplay(ctx context.Context, params *VerifyParams) error {
+	nonce := params.Nonce
+	if nonce == "" {
+		nonce, _ = params.Data["nonce"].(string)
+	}
+	if nonce == "" {
+		if s.requireNonce {
+			return ErrMissingNonce
+		}
+		return nil
+	}
+	if err := checkNonce(nonce); err != nil {
+		return err
+	}
+	if s.nonceStore == nil {
+		return nil
+	}
+	return s.nonceStore.Use(ctx, params.AppID, nonce, s.nonceTTL())
+}
+
+// checkNonce 校验nonce的长度和字符，只允许可见ASCII字符，避免存储时出错
+func checkNonce(nonce string) error {
+	if len(nonce) > MaxNonceLength {
+		return fmt.Errorf("%w: nonce长度超过%d", ErrInvalidRequest, MaxNonceLength)
+	}
+	for i := 0; i < len(nonce); i++ {
+		if nonce[i] < 0x21 || nonce[i] > 0x7e {
+			return fmt.Errorf("%w: nonce包含不可见或非ASCII字符", ErrInvalidRequest)
+		}
+	}
+	return nil
+}
+
+// checkTimestamp 读取并验证请求时间戳
+func (s *SignatureSDK) checkTimestamp(params *VerifyParams, now time.Time) error {
+	timestamp := params.Timestamp
+	if timestamp == 0 {
+		v, ok := params.Data["timestamp"]
+		if !ok || v == nil || v == "" {
+			if s.requireTimestamp {
+				return ErrMissingTimestamp
+			}
+			return nil
+		}
+
+		var err error
+		if timestamp, err = parseTimestamp(v); err != nil {
+			return err
+		}
+	}
+	return s.verifyTimestamp(timestamp, now)
+}
+
+// parseTimestamp 解析时间戳参数，单位为秒，大于1e12的数值按毫秒处理
+func parseTimestamp(v interface{}) (int64, error) {
+	var timestamp int64
+	switch val := v.(type) {
+	case int64:
+		timestamp = val
+	case int:
+		timestamp = int64(val)
+	case float64:
+		timestamp = int64(val)
+	case json.Number:
+		n, err := val.Int64()
+		if err != nil {
+			return 0, fmt.Errorf("%w: 无效的时间戳 %v", ErrInvalidRequest, v)
+		}
+		timestamp = n
+	case string:
+		n, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%w: 无效的时间戳 %v", ErrInvalidRequest, v)
+		}
+		timestamp = n
+	default:
+		return 0, fmt.Errorf("%w: 无效的时间戳 %v", ErrInvalidRequest, v)
+	}
+
+	if timestamp > 1e12 {
+		timestamp /= 1000
+	}
+	return timestamp, nil
+}
+
+// applyTimestampNonce 将参数中的时间戳和nonce写入签名数据
+func applyTimestampNonce(data map[string]interface{}, timestamp int64, nonce string) {
+	if timestamp != 0 {
+		data["timestamp"] = timestamp
+	}
+	if nonce != "" {
+		data["nonce"] = nonce
+	}
+}
@@ -0,0 +1,108 @@
+package go_signature_sdk
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// SecretEventType 密钥变更事件类型
+type SecretEventType string
+
+const (
+	SecretEventRotated SecretEventType = "rotated" // 生成了新的主密钥
+	SecretEventRetired SecretEventType = "retired" // 过期的密钥被清理
+)
+
+// SecretEvent 密钥变更事件
+type SecretEvent struct {
+	Type          SecretEventType `json:"type"`
+	AppID         string          `json:"app_id"`
+	KeyID         string          `json:"key_id"`                    // 新的主密钥或被清理的密钥
+	PreviousKeyID string          `json:"previous_key_id,omitempty"` // 轮换前的主密钥，仅 rotated
+	NotAfter      int64           `json:"not_after,omitempty"`       // 旧主密钥的失效时间戳，仅 rotated
+	Time          time.Time       `json:"time"`
+}
+
+// RetiredSecret 被清理的过期密钥
+type RetiredSecret struct {
+	AppID string `json:"app_id"`
+	KeyID string `json:"key_id"`
+}
+
+// RotateSecret 为应用生成新的随机密钥并设为主密钥
+// 原主密钥在 grace 时间内继续用于验签，之后由 SweepSecrets 清理；grace 为0时原主密钥立即失效
+func (s *SignatureSDK) RotateSecret(ctx context.Context, appID string, grace time.Duration) (*AppSecret, error) {
+	value, err := generateCredential(secretPrefix, credentialEnvironment(appID), secretRandomLen)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.policy.ValidateSecret(value); err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	secret := &AppSecret{KeyID: newKeyID(), Secret: value, Primary: true, CreateAt: now.Unix()}
+	notAfter := now.Add(grace).Unix()
+	previousKeyID, err := s.store.RotateSecret(ctx, appID, secret, notAfter)
+	if err != nil {
+		return nil, err
+	}
+
+	s.emitSecretEvent(SecretEvent{
+		Type:          SecretEventRotated,
+		AppID:         appID,
+		KeyID:         secret.KeyID,
+		PreviousKeyID: previousKeyID,
+		NotAfter:      notAfter,
+		Time:          now,
+	})
+	return secret, nil
+}
+
+// SweepSecrets 删除全部应用中已过期的非主密钥，并为每个密钥发出 retired 事件
+func (s *SignatureSDK) SweepSecrets(ctx context.Context) ([]RetiredSecret, error) {
+	now := s.now()
+	retired, err := s.store.DeleteExpiredSecrets(ctx, now.Unix())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range retired {
+		s.emitSecretEvent(SecretEvent{Type: SecretEventRetired, AppID: r.AppID, KeyID: r.KeyID, Time: now})
+	}
+	return retired, nil
+}
+
+// StartSecretSweeper 启动后台定时清理过期密钥，返回停止函数
+// 停止函数返回时后台清理已经退出，之后可以安全地关闭数据库
+func (s *SignatureSDK) StartSecretSweeper(interval time.Duration) (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := s.SweepSecrets(ctx); err != nil && ctx.Err() == nil {
+					log.Println("清理过期密钥失败:", err)
+				}
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// emitSecretEvent 调用配置的事件回调
+func (s *SignatureSDK) emitSecretEvent(event SecretEvent) {
+	if s.onSecretEvent != nil {
+		s.onSecretEvent(event)
+	}
+}
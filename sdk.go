@@ -1,64 +1,161 @@
 package go_signature_sdk
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"log"
+	"time"
 )
 
 // SignatureSDK 签名SDK
 type SignatureSDK struct {
-	db *sql.DB
+	db            *sql.DB
+	store         KeyStore
+	cache         *cachedKeyStore
+	listener      *invalidationListener
+	signTypeUsage *usageRecorder
+	secretUsage   *usageRecorder
+	policy        *CredentialPolicy
+
+	clock            func() time.Time
+	timestampSkew    time.Duration
+	requireTimestamp bool
+	nonceStore       NonceStore
+	requireNonce     bool
+
+	deleteRetention time.Duration
+
+	onSecretEvent func(SecretEvent)
+}
+
+// New 创建签名SDK实例
+// 配置了 DB 且未设置 SkipMigrations 时先执行数据库迁移，迁移或监听失败时返回错误
+func New(config *Config) (*SignatureSDK, error) {
+	if config.KeyStore == nil && config.DB == nil {
+		return nil, fmt.Errorf("未配置数据库连接或应用密钥存储")
+	}
+	if config.DB != nil && !config.SkipMigrations {
+		if err := Migrate(context.Background(), config.DB); err != nil {
+			return nil, err
+		}
+	}
+
+	sdk, err := newSignatureSDK(config)
+	if err != nil {
+		return nil, err
+	}
+	return sdk, nil
 }
 
 // NewSignatureSDK 创建签名SDK实例
+// 与 New 相同，但错误只记录日志，未指定 KeyStore 时使用 DB 创建 PostgresKeyStore
 func NewSignatureSDK(config *Config) *SignatureSDK {
-	query := `CREATE TABLE IF NOT EXISTS app_keys (	
-    id SERIAL PRIMARY KEY,
-    app_id VARCHAR(32) NOT NULL UNIQUE,
-    secret_key VARCHAR(64) NOT NULL,
-    ips_white JSONB NOT NULL,
-    status SMALLINT NOT NULL DEFAULT 1,
-    attributes JSONB NOT NULL DEFAULT '{}',
-    create_at BIGINT NOT NULL,
-    update_at BIGINT DEFAULT NULL); 
-CREATE INDEX IF NOT EXISTS  idx_app_keys_app_id ON app_keys(app_id);
-CREATE INDEX  IF NOT EXISTS idx_app_keys_status ON app_keys(status);`
-	if _, err := config.DB.Exec(query); err != nil {
-		log.Println("failed to create app_keys table: %w", err)
-	}
-
-	return &SignatureSDK{
-		db: config.DB,
+	if config.DB != nil && !config.SkipMigrations {
+		if err := Migrate(context.Background(), config.DB); err != nil {
+			log.Println("failed to migrate:", err)
+		}
 	}
-}
 
-// GenerateSign 生成签名
-func (s *SignatureSDK) GenerateSign(params *SignParams) (error, string) {
-	// 获取应用密钥
-	appKey, err := s.GetAppKey(params.AppID)
+	sdk, err := newSignatureSDK(config)
 	if err != nil {
-		return err, ""
+		log.Println("failed to listen app_keys changes:", err)
+	}
+	return sdk
+}
+
+// newSignatureSDK 根据配置创建SDK，出错时仍返回可用的实例
+func newSignatureSDK(config *Config) (*SignatureSDK, error) {
+	store := config.KeyStore
+	if store == nil {
+		pgStore := NewPostgresKeyStore(config.DB)
+		pgStore.Encryption = config.KeyEncryption
+		pgStore.RowMACKey = config.RowMACKey
+		pgStore.QueryTimeout = config.QueryTimeout
+		store = pgStore
+	}
+
+	var cache *cachedKeyStore
+	if config.Cache != nil {
+		cache = newCachedKeyStore(store, *config.Cache, config.Clock)
+		store = cache
 	}
 
-	if appKey.Status != 1 {
-		return ErrAppDisabled, ""
+	var listener *invalidationListener
+	var err error
+	if cache != nil && config.ListenDSN != "" {
+		listener, err = newInvalidationListener(config.ListenDSN, cache)
 	}
 
-	// 构建签名字符串
-	sign, s2 := GenerateSign(params.Data, appKey.SecretKey)
-	params.Data["sign"] = sign
-	return nil, s2
+	timestampSkew := config.TimestampSkew
+	if timestampSkew <= 0 {
+		timestampSkew = DefaultTimestampSkew
+	}
+
+	deleteRetention := config.DeleteRetention
+	if deleteRetention <= 0 {
+		deleteRetention = DefaultDeleteRetention
+	}
+
+	sdk := &SignatureSDK{
+		db:               config.DB,
+		store:            store,
+		cache:            cache,
+		listener:         listener,
+		signTypeUsage:    newUsageRecorder(),
+		secretUsage:      newUsageRecorder(),
+		policy:           config.CredentialPolicy,
+		clock:            config.Clock,
+		timestampSkew:    timestampSkew,
+		requireTimestamp: config.RequireTimestamp,
+		nonceStore:       config.NonceStore,
+		requireNonce:     config.RequireNonce,
+		deleteRetention:  deleteRetention,
+		onSecretEvent:    config.OnSecretEvent,
+	}
+	return sdk, err
+}
+
+// GenerateSign 生成签名，签名、时间戳和nonce会写入 params.Data，第二个返回值为脱敏后的签名字符串
+//
+// Deprecated: 使用 Sign，它按 (签名, error) 返回且不修改 params.Data。
+func (s *SignatureSDK) GenerateSign(params *SignParams) (error, string) {
+	return s.GenerateSignContext(context.Background(), params)
+}
+
+// GenerateSignContext 与 GenerateSign 相同，ctx 用于查询应用
+//
+// Deprecated: 使用 Sign。
+func (s *SignatureSDK) GenerateSignContext(ctx context.Context, params *SignParams) (error, string) {
+	sign, signStr, err := s.sign(ctx, params, newOptions(nil))
+	if err != nil {
+		return err, ""
+	}
+	applyTimestampNonce(params.Data, params.Timestamp, params.Nonce)
+	params.Data[DefaultSignField] = sign
+	return nil, signStr
 }
 
 // VerifyIPs 验证IP和获取应用密钥
 func (s *SignatureSDK) VerifyIPs(AppID, clientIP string) (*AppKey, error) {
-	appKey, err := s.GetAppKey(AppID)
+	return s.VerifyIPsContext(context.Background(), AppID, clientIP)
+}
+
+// VerifyIPsContext 与 VerifyIPs 相同，ctx 取消或超时后查询立即返回
+func (s *SignatureSDK) VerifyIPsContext(ctx context.Context, appID, clientIP string) (*AppKey, error) {
+	// 校验码错误的应用ID无需查询存储
+	if err := checkAppIDFormat(appID); err != nil {
+		return nil, err
+	}
+
+	appKey, err := s.GetAppKeyContext(ctx, appID)
 	if err != nil {
 		return nil, err
 	}
 
-	if appKey.Status != 1 {
-		return nil, ErrAppDisabled
+	if err := statusError(appKey.Status); err != nil {
+		return nil, err
 	}
 
 	// 验证IP白名单
@@ -69,11 +166,50 @@ func (s *SignatureSDK) VerifyIPs(AppID, clientIP string) (*AppKey, error) {
 }
 
 // VerifySign 验证签名
+//
+// Deprecated: 使用 Verify，它同时返回匹配的应用、算法和密钥ID。
 func (s *SignatureSDK) VerifySign(params *VerifyParams) error {
-	// 获取应用密钥
-	appKey, err := s.VerifyIPs(params.AppID, params.ClientIP)
+	return s.VerifySignContext(context.Background(), params)
+}
+
+// VerifySignContext 与 VerifySign 相同，ctx 用于查询应用和登记nonce
+//
+// Deprecated: 使用 Verify。
+func (s *SignatureSDK) VerifySignContext(ctx context.Context, params *VerifyParams) error {
+	_, _, err := s.verifySign(ctx, params)
+	return err
+}
+
+// VerifySignKeyID 验证签名并返回匹配的密钥ID，非对称算法的密钥ID为空
+//
+// Deprecated: 使用 Verify，密钥ID见 VerifyResult.KeyID。
+func (s *SignatureSDK) VerifySignKeyID(params *VerifyParams) (string, error) {
+	return s.VerifySignKeyIDContext(context.Background(), params)
+}
+
+// VerifySignKeyIDContext 与 VerifySignKeyID 相同，ctx 用于查询应用和登记nonce
+//
+// Deprecated: 使用 Verify。
+func (s *SignatureSDK) VerifySignKeyIDContext(ctx context.Context, params *VerifyParams) (string, error) {
+	_, keyID, err := s.verifySign(ctx, params)
+	return keyID, err
+}
+
+// verifySign 验证签名，返回通过验证的应用和匹配的密钥ID；错误为原始错误，不包装为 *VerifyError
+// 与旧版本一致，签名不一致时通过标准库 log 输出脱敏的签名字符串
+func (s *SignatureSDK) verifySign(ctx context.Context, params *VerifyParams) (*AppKey, string, error) {
+	result, err := s.verify(ctx, params, newOptions([]Option{WithLogger(log.Default())}))
 	if err != nil {
-		return err
+		return nil, "", errors.Unwrap(err)
+	}
+	return result.AppKey, result.KeyID, nil
+}
+
+// SignTypeUsage 返回本实例中应用各签名算法的验签成功次数，用于判断迁移进度，多实例汇总见 StartUsageExporter
+func (s *SignatureSDK) SignTypeUsage(appID string) map[SignType]UsageStat {
+	result := make(map[SignType]UsageStat)
+	for k, v := range s.signTypeUsage.snapshot(appID) {
+		result[SignType(k)] = v
 	}
-	return VerifySign(params, appKey.SecretKey)
+	return result
 }
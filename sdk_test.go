@@ -1,8 +1,22 @@
 package go_signature_sdk
 
 import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
 	"database/sql"
+	"encoding/pem"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -18,6 +32,9 @@ const (
 	testDBName     = "test_signature_sdk"
 )
 
+// dropTestTables 清理测试表，包括迁移记录
+const dropTestTables = "DROP TABLE IF EXISTS app_secrets, app_keys, app_nonces, app_tombstones, app_key_events, schema_migrations"
+
 // setupTestDB 设置测试数据库
 func setupTestDB(t *testing.T) *sql.DB {
 	// 连接测试数据库
@@ -34,7 +51,7 @@ func setupTestDB(t *testing.T) *sql.DB {
 	}
 
 	// 清理测试表
-	_, err = db.Exec("DROP TABLE IF EXISTS app_keys")
+	_, err = db.Exec(dropTestTables)
 	if err != nil {
 		t.Fatalf("清理测试表失败: %v", err)
 	}
@@ -45,7 +62,7 @@ func setupTestDB(t *testing.T) *sql.DB {
 // teardownTestDB 清理测试数据库
 func teardownTestDB(t *testing.T, db *sql.DB) {
 	if db != nil {
-		db.Exec("DROP TABLE IF EXISTS app_keys")
+		db.Exec(dropTestTables)
 		db.Close()
 	}
 }
@@ -54,10 +71,475 @@ func teardownTestDB(t *testing.T, db *sql.DB) {
 func createTestSDK(t *testing.T) (*SignatureSDK, *sql.DB) {
 	db := setupTestDB(t)
 	config := &Config{DB: db}
-	sdk := NewSignatureSDK(config)
+	sdk, err := New(config)
+	if err != nil {
+		t.Fatalf("创建SDK失败: %v", err)
+	}
 	return sdk, db
 }
 
+// createMemorySDK 创建使用内存存储的SDK实例
+func createMemorySDK(t *testing.T) *SignatureSDK {
+	return NewSignatureSDK(&Config{KeyStore: NewMemoryKeyStore()})
+}
+
+// TestKeyStores 测试内存和文件存储
+func TestKeyStores(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app_keys.json")
+	fileStore, err := NewFileKeyStore(path)
+	if err != nil {
+		t.Fatalf("创建文件存储失败: %v", err)
+	}
+
+	stores := map[string]KeyStore{
+		"memory": NewMemoryKeyStore(),
+		"file":   fileStore,
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			sdk := NewSignatureSDK(&Config{KeyStore: store})
+
+			attributes := map[string]interface{}{"env": "test", "nested": map[string]interface{}{"a": "b"}}
+			if err := sdk.CreateAppKey("test_app", "test_secret", []string{"127.0.0.1"}, attributes); err != nil {
+				t.Fatalf("创建应用失败: %v", err)
+			}
+			if err := sdk.CreateAppKey("test_app", "test_secret", nil, nil); !errors.Is(err, ErrAppExists) {
+				t.Errorf("期望应用已存在错误, 实际: %v", err)
+			}
+
+			// 修改返回值不影响存储
+			appKey, err := sdk.GetAppKey("test_app")
+			if err != nil {
+				t.Fatalf("获取应用失败: %v", err)
+			}
+			appKey.IPsWhite[0] = "10.0.0.1"
+			appKey.Attributes["nested"].(map[string]interface{})["a"] = "c"
+			appKey, _ = sdk.GetAppKey("test_app")
+			if appKey.IPsWhite[0] != "127.0.0.1" || appKey.Attributes["nested"].(map[string]interface{})["a"] != "b" {
+				t.Errorf("存储被外部修改: %+v", appKey)
+			}
+
+			if err := sdk.UpdateAppKey("test_app", "new_secret", []string{"10.0.0.0/8"}, 0, nil); err != nil {
+				t.Fatalf("更新应用失败: %v", err)
+			}
+			appKey, _ = sdk.GetAppKey("test_app")
+			if appKey.SecretKey != "new_secret" || appKey.Status != 0 || appKey.UpdateAt == nil {
+				t.Errorf("更新结果错误: %+v", appKey)
+			}
+
+			if err := sdk.UpdateAppKey("not_exist", "s", nil, 1, nil); err != ErrAppNotFound {
+				t.Errorf("期望应用不存在错误, 实际: %v", err)
+			}
+			if _, err := sdk.GetAppKey("not_exist"); err != ErrAppNotFound {
+				t.Errorf("期望应用不存在错误, 实际: %v", err)
+			}
+		})
+	}
+
+	// 重新加载文件
+	reloaded, err := NewFileKeyStore(path)
+	if err != nil {
+		t.Fatalf("重新加载文件存储失败: %v", err)
+	}
+	appKey, err := reloaded.GetAppKey(context.Background(), "test_app")
+	if err != nil || appKey.SecretKey != "new_secret" || appKey.ID != 1 {
+		t.Errorf("文件存储内容错误: %+v, %v", appKey, err)
+	}
+}
+
+// TestFileKeyStoreEvents 测试文件存储的审计记录只追加写入单独的文件
+func TestFileKeyStoreEvents(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "app_keys.json")
+	open := func() *SignatureSDK {
+		store, err := NewFileKeyStore(path)
+		if err != nil {
+			t.Fatalf("打开文件存储失败: %v", err)
+		}
+		return NewSignatureSDK(&Config{KeyStore: store})
+	}
+
+	sdk := open()
+	if err := sdk.CreateAppKey("test_app", "test_secret", nil, nil); err != nil {
+		t.Fatalf("创建测试应用失败: %v", err)
+	}
+	if err := sdk.MergeAttributes(ctx, "test_app", map[string]interface{}{"tier": "gold"}); err != nil {
+		t.Fatalf("修改应用失败: %v", err)
+	}
+	if content, _ := os.ReadFile(path); strings.Contains(string(content), `"snapshot"`) {
+		t.Errorf("审计记录不应写入密钥文件: %s", content)
+	}
+
+	// 写入失败的变更留下的记录在重新打开时被忽略，并在下次写入时被截掉
+	events, err := os.OpenFile(path+".events.jsonl", os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		t.Fatalf("打开审计记录文件失败: %v", err)
+	}
+	events.WriteString(`{"id":3,"app_id":"test_app"`)
+	events.Close()
+
+	sdk = open()
+	if history, err := sdk.History(ctx, "test_app"); err != nil || len(history) != 2 {
+		t.Fatalf("重新打开后审计记录错误: %+v, %v", history, err)
+	}
+	if err := sdk.MergeAttributes(ctx, "test_app", map[string]interface{}{"tier": "silver"}); err != nil {
+		t.Fatalf("修改应用失败: %v", err)
+	}
+
+	sdk = open()
+	history, err := sdk.History(ctx, "test_app")
+	if err != nil || len(history) != 3 || history[2].ID != 3 || history[2].Snapshot.Attributes["tier"] != "silver" {
+		t.Fatalf("追加后审计记录错误: %+v, %v", history, err)
+	}
+}
+
+// TestMemorySDKVerifySign 测试使用内存存储的SDK签名和验签
+func TestMemorySDKVerifySign(t *testing.T) {
+	sdk := createMemorySDK(t)
+
+	appID := "test_app_memory"
+	if err := sdk.CreateAppKey(appID, "test_secret_memory", []string{"127.0.0.1"}, nil); err != nil {
+		t.Fatalf("创建测试应用失败: %v", err)
+	}
+
+	params := &SignParams{
+		AppID:     appID,
+		Timestamp: time.Now().Unix(),
+		Nonce:     "test_nonce",
+		Data:      map[string]interface{}{"user_id": "12345"},
+	}
+	if err, s := sdk.GenerateSign(params); err != nil {
+		t.Fatalf("SDK签名生成失败:%s, %v", s, err)
+	}
+
+	verify := func(clientIP string) error {
+		return sdk.VerifySign(&VerifyParams{AppID: appID, Data: copyData(params.Data), ClientIP: clientIP})
+	}
+	if err := verify("127.0.0.1"); err != nil {
+		t.Errorf("签名验证失败: %v", err)
+	}
+	if err := verify("10.0.0.1"); err != ErrIPNotAllowed {
+		t.Errorf("期望IP不在白名单错误, 实际: %v", err)
+	}
+
+	if err := sdk.UpdateAppKey(appID, "test_secret_memory", []string{"127.0.0.1"}, 0, nil); err != nil {
+		t.Fatalf("禁用应用失败: %v", err)
+	}
+	if err := verify("127.0.0.1"); !errors.Is(err, ErrAppSuspended) || !errors.Is(err, ErrAppDisabled) {
+		t.Errorf("期望应用已禁用错误, 实际: %v", err)
+	}
+}
+
+// TestAppSecrets 测试多密钥轮换
+func TestAppSecrets(t *testing.T) {
+	testAppSecrets(t, createMemorySDK(t))
+}
+
+// TestSDKAppSecrets 测试PostgreSQL存储的多密钥轮换
+func TestSDKAppSecrets(t *testing.T) {
+	sdk, db := createTestSDK(t)
+	defer teardownTestDB(t, db)
+	testAppSecrets(t, sdk)
+}
+
+func testAppSecrets(t *testing.T, sdk *SignatureSDK) {
+	ctx := context.Background()
+	appID := "test_app_secrets"
+	if err := sdk.CreateAppKey(appID, "old_secret", []string{}, nil); err != nil {
+		t.Fatalf("创建测试应用失败: %v", err)
+	}
+	appKey, err := sdk.GetAppKey(appID)
+	if err != nil {
+		t.Fatalf("获取应用失败: %v", err)
+	}
+	if len(appKey.Secrets) != 1 || !appKey.Secrets[0].Primary || appKey.Secrets[0].Secret != "old_secret" {
+		t.Fatalf("初始密钥错误: %+v", appKey.Secrets)
+	}
+	oldKeyID := appKey.Secrets[0].KeyID
+
+	sign := func() map[string]interface{} {
+		params := &SignParams{AppID: appID, Data: map[string]interface{}{"user_id": "12345"}}
+		if err, _ := sdk.GenerateSign(params); err != nil {
+			t.Fatalf("签名生成失败: %v", err)
+		}
+		return params.Data
+	}
+	verify := func(data map[string]interface{}) (string, error) {
+		return sdk.VerifySignKeyID(&VerifyParams{AppID: appID, Data: copyData(data), ClientIP: "127.0.0.1"})
+	}
+	oldSigned := sign()
+
+	// 新密钥成为主密钥，旧密钥签名仍然有效
+	newSecret := &AppSecret{Secret: "new_secret", Primary: true}
+	if err := sdk.AddSecret(ctx, appID, newSecret); err != nil {
+		t.Fatalf("添加密钥失败: %v", err)
+	}
+	if err := sdk.AddSecret(ctx, appID, &AppSecret{KeyID: newSecret.KeyID, Secret: "other"}); !errors.Is(err, ErrSecretExists) {
+		t.Errorf("期望密钥ID已存在错误, 实际: %v", err)
+	}
+	appKey, _ = sdk.GetAppKey(appID)
+	if appKey.SecretKey != "new_secret" || len(appKey.Secrets) != 2 {
+		t.Fatalf("主密钥未更新: %+v", appKey)
+	}
+
+	newSigned := sign()
+	if keyID, err := verify(newSigned); err != nil || keyID != newSecret.KeyID {
+		t.Errorf("新密钥验证失败: %s, %v", keyID, err)
+	}
+	if keyID, err := verify(oldSigned); err != nil || keyID != oldKeyID {
+		t.Errorf("旧密钥验证失败: %s, %v", keyID, err)
+	}
+	usage := sdk.SecretUsage(appID)
+	if usage[oldKeyID].Count != 1 || usage[newSecret.KeyID].Count != 1 {
+		t.Errorf("密钥使用统计错误: %v", usage)
+	}
+
+	if err := sdk.RemoveSecret(ctx, appID, newSecret.KeyID); err != ErrPrimarySecret {
+		t.Errorf("期望不能删除主密钥错误, 实际: %v", err)
+	}
+	if err := sdk.RemoveSecret(ctx, appID, "missing"); err != ErrSecretNotFound {
+		t.Errorf("期望密钥不存在错误, 实际: %v", err)
+	}
+	if err := sdk.RemoveSecret(ctx, appID, oldKeyID); err != nil {
+		t.Fatalf("删除旧密钥失败: %v", err)
+	}
+	if _, err := verify(oldSigned); err != ErrInvalidSign {
+		t.Errorf("期望旧密钥签名失败, 实际: %v", err)
+	}
+
+	// 已过期的密钥不再接受
+	expired := &AppSecret{Secret: "expired_secret", NotAfter: time.Now().Add(-time.Minute).Unix()}
+	if err := sdk.AddSecret(ctx, appID, expired); err != nil {
+		t.Fatalf("添加密钥失败: %v", err)
+	}
+	expiredSigned := map[string]interface{}{"user_id": "12345"}
+	expiredSigned["sign"], _ = GenerateSign(expiredSigned, "expired_secret")
+	if _, err := verify(expiredSigned); err != ErrInvalidSign {
+		t.Errorf("期望过期密钥签名失败, 实际: %v", err)
+	}
+
+	// 切回主密钥
+	if err := sdk.SetPrimarySecret(ctx, appID, expired.KeyID); err != nil {
+		t.Fatalf("设置主密钥失败: %v", err)
+	}
+	if appKey, _ = sdk.GetAppKey(appID); appKey.SecretKey != "expired_secret" {
+		t.Errorf("主密钥未更新: %s", appKey.SecretKey)
+	}
+	if err := sdk.SetPrimarySecret(ctx, appID, "missing"); err != ErrSecretNotFound {
+		t.Errorf("期望密钥不存在错误, 实际: %v", err)
+	}
+
+	// UpdateAppKey 替换主密钥
+	if err := sdk.UpdateAppKey(appID, "updated_secret", []string{}, 1, nil); err != nil {
+		t.Fatalf("更新应用失败: %v", err)
+	}
+	appKey, _ = sdk.GetAppKey(appID)
+	if primary := appKey.primarySecret(); primary == nil || primary.Secret != "updated_secret" || primary.KeyID != expired.KeyID {
+		t.Errorf("主密钥未同步: %+v", appKey.Secrets)
+	}
+}
+
+// TestRotateSecret 测试密钥轮换和过期清理
+func TestRotateSecret(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	var events []SecretEvent
+	sdk := NewSignatureSDK(&Config{
+		KeyStore:      NewMemoryKeyStore(),
+		Clock:         func() time.Time { return now },
+		OnSecretEvent: func(e SecretEvent) { events = append(events, e) },
+	})
+	ctx := context.Background()
+
+	appID := "test_app_rotate"
+	if err := sdk.CreateAppKey(appID, "old_secret", []string{}, nil); err != nil {
+		t.Fatalf("创建测试应用失败: %v", err)
+	}
+	appKey, _ := sdk.GetAppKey(appID)
+	oldKeyID := appKey.Secrets[0].KeyID
+
+	oldSigned := map[string]interface{}{"user_id": "12345"}
+	oldSigned["sign"], _ = GenerateSign(oldSigned, "old_secret")
+	verify := func(data map[string]interface{}) error {
+		return sdk.VerifySign(&VerifyParams{AppID: appID, Data: copyData(data), ClientIP: "127.0.0.1"})
+	}
+
+	secret, err := sdk.RotateSecret(ctx, appID, time.Hour)
+	if err != nil {
+		t.Fatalf("轮换密钥失败: %v", err)
+	}
+	if err := ValidateCredential(secret.Secret); err != nil || !strings.HasPrefix(secret.Secret, "sk_live_") {
+		t.Errorf("新密钥格式错误: %q", secret.Secret)
+	}
+	if appKey, _ = sdk.GetAppKey(appID); appKey.SecretKey != secret.Secret {
+		t.Errorf("新密钥未成为主密钥")
+	}
+	if len(events) != 1 || events[0].Type != SecretEventRotated || events[0].PreviousKeyID != oldKeyID ||
+		events[0].KeyID != secret.KeyID || events[0].NotAfter != now.Add(time.Hour).Unix() {
+		t.Errorf("轮换事件错误: %+v", events)
+	}
+
+	// 宽限期内旧密钥仍然有效，清理不删除
+	if err := verify(oldSigned); err != nil {
+		t.Errorf("宽限期内旧密钥验证失败: %v", err)
+	}
+	if retired, err := sdk.SweepSecrets(ctx); err != nil || len(retired) != 0 {
+		t.Errorf("宽限期内不应清理密钥: %v, %v", retired, err)
+	}
+
+	// 宽限期结束后旧密钥失效并被清理
+	now = now.Add(time.Hour)
+	if err := verify(oldSigned); err != ErrInvalidSign {
+		t.Errorf("期望旧密钥验证失败, 实际: %v", err)
+	}
+	retired, err := sdk.SweepSecrets(ctx)
+	if err != nil || len(retired) != 1 || retired[0] != (RetiredSecret{AppID: appID, KeyID: oldKeyID}) {
+		t.Fatalf("清理过期密钥错误: %v, %v", retired, err)
+	}
+	if len(events) != 2 || events[1].Type != SecretEventRetired || events[1].KeyID != oldKeyID {
+		t.Errorf("清理事件错误: %+v", events)
+	}
+	if appKey, _ = sdk.GetAppKey(appID); len(appKey.Secrets) != 1 || !appKey.Secrets[0].Primary {
+		t.Errorf("清理后密钥错误: %+v", appKey.Secrets)
+	}
+
+	if _, err := sdk.RotateSecret(ctx, "missing", time.Hour); err != ErrAppNotFound {
+		t.Errorf("期望应用不存在错误, 实际: %v", err)
+	}
+}
+
+// blockingSweepKeyStore 清理过期密钥时通知 started 并等待一段时间的存储
+type blockingSweepKeyStore struct {
+	KeyStore
+	started  chan struct{}
+	finished atomic.Bool
+}
+
+func (b *blockingSweepKeyStore) DeleteExpiredSecrets(ctx context.Context, now int64) ([]RetiredSecret, error) {
+	select {
+	case b.started <- struct{}{}:
+	default:
+	}
+	time.Sleep(20 * time.Millisecond)
+	defer b.finished.Store(true)
+	return b.KeyStore.DeleteExpiredSecrets(ctx, now)
+}
+
+// TestSecretSweeperStop 测试停止函数等待进行中的清理结束
+func TestSecretSweeperStop(t *testing.T) {
+	store := &blockingSweepKeyStore{KeyStore: NewMemoryKeyStore(), started: make(chan struct{})}
+	sdk := NewSignatureSDK(&Config{KeyStore: store})
+
+	stop := sdk.StartSecretSweeper(time.Millisecond)
+	<-store.started
+	stop()
+	if !store.finished.Load() {
+		t.Error("停止函数返回时清理仍在进行")
+	}
+}
+
+// countingKeyStore 统计查询次数的存储，release 非空时查询会等待其关闭
+type countingKeyStore struct {
+	KeyStore
+	gets    atomic.Int64
+	release chan struct{}
+}
+
+func (c *countingKeyStore) GetAppKey(ctx context.Context, appID string) (*AppKey, error) {
+	c.gets.Add(1)
+	if c.release != nil {
+		<-c.release
+	}
+	return c.KeyStore.GetAppKey(ctx, appID)
+}
+
+// TestAppKeyCache 测试应用密钥缓存
+func TestAppKeyCache(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	store := &countingKeyStore{KeyStore: NewMemoryKeyStore()}
+	sdk := NewSignatureSDK(&Config{
+		KeyStore: store,
+		Cache:    &CacheConfig{MaxEntries: 2, TTL: time.Minute, NegativeTTL: time.Second},
+		Clock:    func() time.Time { return now },
+	})
+
+	for _, appID := range []string{"app_1", "app_2", "app_3"} {
+		if err := sdk.CreateAppKey(appID, "secret_"+appID, nil, nil); err != nil {
+			t.Fatalf("创建应用失败: %v", err)
+		}
+	}
+
+	// 第二次命中缓存
+	sdk.GetAppKey("app_1")
+	appKey, _ := sdk.GetAppKey("app_1")
+	if store.gets.Load() != 1 || appKey.SecretKey != "secret_app_1" {
+		t.Errorf("缓存未命中: gets=%d", store.gets.Load())
+	}
+
+	// 更新后自动失效
+	if err := sdk.UpdateAppKey("app_1", "new_secret", nil, 1, nil); err != nil {
+		t.Fatalf("更新应用失败: %v", err)
+	}
+	if appKey, _ := sdk.GetAppKey("app_1"); appKey.SecretKey != "new_secret" {
+		t.Errorf("更新后读取到旧数据: %s", appKey.SecretKey)
+	}
+
+	// 不存在的应用短时间缓存
+	sdk.GetAppKey("not_exist")
+	if _, err := sdk.GetAppKey("not_exist"); err != ErrAppNotFound {
+		t.Errorf("期望应用不存在错误, 实际: %v", err)
+	}
+	if stats := sdk.CacheStats(); stats.NegativeHits != 1 {
+		t.Errorf("负缓存未命中: %+v", stats)
+	}
+	now = now.Add(2 * time.Second)
+	gets := store.gets.Load()
+	sdk.GetAppKey("not_exist")
+	if store.gets.Load() != gets+1 {
+		t.Error("负缓存过期后应重新查询")
+	}
+
+	// 超出容量淘汰最久未使用的条目
+	sdk.GetAppKey("app_2")
+	sdk.GetAppKey("app_3")
+	if stats := sdk.CacheStats(); stats.Entries != 2 || stats.Evictions == 0 {
+		t.Errorf("容量淘汰错误: %+v", stats)
+	}
+
+	// 过期后重新查询
+	now = now.Add(2 * time.Minute)
+	gets = store.gets.Load()
+	sdk.GetAppKey("app_3")
+	if store.gets.Load() != gets+1 {
+		t.Error("缓存过期后应重新查询")
+	}
+
+	// 并发未命中合并为一次查询
+	sdk.PurgeAppKeyCache()
+	store.release = make(chan struct{})
+	gets = store.gets.Load()
+	misses := sdk.CacheStats().Misses
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := sdk.GetAppKey("app_2"); err != nil {
+				t.Errorf("获取应用失败: %v", err)
+			}
+		}()
+	}
+	// 等待所有请求都已未命中，再放行存储查询
+	for deadline := time.Now().Add(time.Second); sdk.CacheStats().Misses < misses+10 && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	close(store.release)
+	wg.Wait()
+	if store.gets.Load() != gets+1 {
+		t.Errorf("并发查询未合并: %d", store.gets.Load()-gets)
+	}
+}
+
 // TestNewSignatureSDK 测试SDK创建
 func TestNewSignatureSDK(t *testing.T) {
 	sdk, db := createTestSDK(t)
@@ -288,6 +770,289 @@ func TestVerifySign(t *testing.T) {
 	}
 }
 
+// TestSigners 测试内置签名算法
+func TestSigners(t *testing.T) {
+	data := map[string]interface{}{
+		"app_id":    "test_app",
+		"timestamp": "1234567890",
+		"nonce":     "abc123",
+	}
+	key := "test_secret"
+
+	// MD5与原有签名方式保持一致
+	sign, _, err := GenerateSignWith(SignTypeMD5, data, key)
+	if err != nil {
+		t.Fatalf("MD5签名失败: %v", err)
+	}
+	if expected := md5Hash(buildSignString(data, key)); sign != expected {
+		t.Errorf("MD5签名不兼容: 期望 %s, 实际 %s", expected, sign)
+	}
+
+	// HMAC-SHA256已知结果
+	signer, err := GetSigner(SignTypeHMACSHA256)
+	if err != nil {
+		t.Fatalf("获取签名算法失败: %v", err)
+	}
+	mac, _ := signer.Sign("The quick brown fox jumps over the lazy dog", "key")
+	if mac != "F7BC83F430538424B13298E6AA6FB143EF4D59A14946175997479DBC2D1A3CD8" {
+		t.Errorf("HMAC-SHA256结果错误: %s", mac)
+	}
+
+	for _, signType := range []SignType{SignTypeMD5, SignTypeHMACSHA256, SignTypeHMACSHA512} {
+		t.Run(string(signType), func(t *testing.T) {
+			sign, _, err := GenerateSignWith(signType, data, key)
+			if err != nil {
+				t.Fatalf("签名生成失败: %v", err)
+			}
+
+			params := &VerifyParams{AppID: "test_app", Data: copyData(data)}
+			params.Data["sign"] = sign
+			if err := VerifySignWith(params, signType, key); err != nil {
+				t.Errorf("签名验证失败: %v", err)
+			}
+
+			params.Data["sign"] = sign
+			if err := VerifySignWith(params, signType, "wrong_secret"); err != ErrInvalidSign {
+				t.Errorf("期望签名验证失败, 实际: %v", err)
+			}
+		})
+	}
+
+	if _, _, err := GenerateSignWith("UNKNOWN", data, key); !errors.Is(err, ErrUnsupportedSignType) {
+		t.Errorf("期望不支持的算法错误, 实际: %v", err)
+	}
+}
+
+// TestAcceptedSignTypes 测试迁移期间的多算法验签
+func TestAcceptedSignTypes(t *testing.T) {
+	appKey := &AppKey{
+		AppID:            "test_app",
+		SignType:         SignTypeHMACSHA256,
+		AllowedSignTypes: []SignType{SignTypeMD5},
+		SecretKey:        "test_secret",
+	}
+	key := appKey.SecretKey
+	keysFor := func(t SignType) []verifyKey { return appKey.verifyKeys(t, time.Now()) }
+	data := map[string]interface{}{"nonce": "abc123"}
+
+	for _, signType := range []SignType{SignTypeMD5, SignTypeHMACSHA256} {
+		sign, _, _ := GenerateSignWith(signType, data, key)
+		params := &VerifyParams{AppID: appKey.AppID, Data: copyData(data)}
+		params.Data["sign"] = sign
+
+		signTypes, err := appKey.acceptedSignTypes(params.Data)
+		if err != nil {
+			t.Fatalf("获取可接受算法失败: %v", err)
+		}
+		used, _, err := verifyAnySignType(withoutField(params.Data, "sign"), sign, signTypes, keysFor, nil)
+		if err != nil {
+			t.Errorf("%s 签名验证失败: %v", signType, err)
+		}
+		if used != signType {
+			t.Errorf("匹配算法错误: 期望 %s, 实际 %s", signType, used)
+		}
+	}
+
+	// 请求指定了不允许的算法
+	if _, err := appKey.acceptedSignTypes(map[string]interface{}{"sign_type": "HMAC-SHA512"}); !errors.Is(err, ErrSignTypeNotAllowed) {
+		t.Errorf("期望算法不允许错误, 实际: %v", err)
+	}
+
+	// 切换为仅HMAC-SHA256后拒绝MD5
+	appKey.AllowedSignTypes = nil
+	sign, _ := GenerateSign(data, key)
+	params := &VerifyParams{AppID: appKey.AppID, Data: copyData(data)}
+	params.Data["sign"] = sign
+	signTypes, _ := appKey.acceptedSignTypes(params.Data)
+	if _, _, err := verifyAnySignType(withoutField(params.Data, "sign"), sign, signTypes, keysFor, nil); err != ErrInvalidSign {
+		t.Errorf("期望签名验证失败, 实际: %v", err)
+	}
+}
+
+// TestAsymmetricSigners 测试非对称签名算法
+func TestAsymmetricSigners(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	testCases := []struct {
+		signType   SignType
+		privateKey interface{}
+		publicKey  interface{}
+	}{
+		{SignTypeRSA2, rsaKey, &rsaKey.PublicKey},
+		{SignTypeRSAPSS, rsaKey, &rsaKey.PublicKey},
+		{SignTypeECDSAP256, ecKey, &ecKey.PublicKey},
+		{SignTypeEd25519, edKey, edKey.Public()},
+	}
+
+	data := map[string]interface{}{
+		"user_id": "12345",
+		"action":  "login",
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.signType), func(t *testing.T) {
+			privatePEM, publicPEM := encodeTestKeyPair(t, tc.privateKey, tc.publicKey)
+
+			sign, _, err := GenerateSignWith(tc.signType, data, privatePEM)
+			if err != nil {
+				t.Fatalf("签名生成失败: %v", err)
+			}
+
+			params := &VerifyParams{Data: copyData(data)}
+			params.Data["sign"] = sign
+			if err := VerifySignWith(params, tc.signType, publicPEM); err != nil {
+				t.Errorf("签名验证失败: %v", err)
+			}
+
+			// 篡改参数后验证失败
+			params.Data["sign"] = sign
+			params.Data["action"] = "logout"
+			if err := VerifySignWith(params, tc.signType, publicPEM); err != ErrInvalidSign {
+				t.Errorf("期望签名验证失败, 实际: %v", err)
+			}
+
+			if err := ValidatePublicKey(tc.signType, publicPEM); err != nil {
+				t.Errorf("公钥校验失败: %v", err)
+			}
+		})
+	}
+
+	// 公钥类型与算法不匹配
+	_, ecPublicPEM := encodeTestKeyPair(t, ecKey, &ecKey.PublicKey)
+	if err := ValidatePublicKey(SignTypeRSA2, ecPublicPEM); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("期望无效密钥错误, 实际: %v", err)
+	}
+}
+
+// encodeTestKeyPair 将密钥对编码为PEM
+func encodeTestKeyPair(t *testing.T, privateKey, publicKey interface{}) (string, string) {
+	privateDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		t.Fatalf("编码私钥失败: %v", err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		t.Fatalf("编码公钥失败: %v", err)
+	}
+	privatePEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER})
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+	return string(privatePEM), string(publicPEM)
+}
+
+// TestTimestampAndNonce 测试时间戳和nonce防重放
+func TestTimestampAndNonce(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	clock := func() time.Time { return now }
+	store := NewMemoryNonceStore()
+	store.Clock = clock
+
+	sdk := &SignatureSDK{
+		clock:            clock,
+		timestampSkew:    DefaultTimestampSkew,
+		requireTimestamp: true,
+		nonceStore:       store,
+		requireNonce:     true,
+	}
+
+	testCases := []struct {
+		name      string
+		params    *VerifyParams
+		expectErr error
+	}{
+		{
+			name:      "时间戳在范围内",
+			params:    &VerifyParams{Timestamp: now.Unix() - 60, Data: map[string]interface{}{}},
+			expectErr: nil,
+		},
+		{
+			name:      "Data中的字符串时间戳",
+			params:    &VerifyParams{Data: map[string]interface{}{"timestamp": "1700000100"}},
+			expectErr: nil,
+		},
+		{
+			name:      "毫秒时间戳",
+			params:    &VerifyParams{Data: map[string]interface{}{"timestamp": now.UnixMilli()}},
+			expectErr: nil,
+		},
+		{
+			name:      "时间戳过期",
+			params:    &VerifyParams{Timestamp: now.Unix() - 301, Data: map[string]interface{}{}},
+			expectErr: ErrExpiredRequest,
+		},
+		{
+			name:      "时间戳超前",
+			params:    &VerifyParams{Timestamp: now.Unix() + 301, Data: map[string]interface{}{}},
+			expectErr: ErrExpiredRequest,
+		},
+		{
+			name:      "缺少时间戳",
+			params:    &VerifyParams{Data: map[string]interface{}{}},
+			expectErr: ErrMissingTimestamp,
+		},
+		{
+			name:      "无效的时间戳",
+			params:    &VerifyParams{Data: map[string]interface{}{"timestamp": "yesterday"}},
+			expectErr: ErrInvalidRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := sdk.checkTimestamp(tc.params, sdk.now()); !errors.Is(err, tc.expectErr) {
+				t.Errorf("期望错误 %v, 实际错误 %v", tc.expectErr, err)
+			}
+		})
+	}
+
+	// nonce去重
+	params := &VerifyParams{AppID: "test_app", Nonce: "abc123", Data: map[string]interface{}{}}
+	if err := sdk.verifyReplay(context.Background(), params); err != nil {
+		t.Fatalf("首次使用nonce失败: %v", err)
+	}
+	if err := sdk.verifyReplay(context.Background(), params); err != ErrReplayedRequest {
+		t.Errorf("期望重放错误, 实际: %v", err)
+	}
+
+	// 其他应用的相同nonce不受影响
+	if err := sdk.verifyReplay(context.Background(), &VerifyParams{AppID: "other_app", Nonce: "abc123"}); err != nil {
+		t.Errorf("其他应用使用nonce失败: %v", err)
+	}
+
+	if err := sdk.verifyReplay(context.Background(), &VerifyParams{AppID: "test_app", Data: map[string]interface{}{}}); err != ErrMissingNonce {
+		t.Errorf("期望缺少nonce错误, 实际: %v", err)
+	}
+
+	// 过长或包含不可见字符的nonce不登记
+	for _, nonce := range []string{strings.Repeat("n", MaxNonceLength+1), "abc 123", "随机数"} {
+		if err := sdk.verifyReplay(context.Background(), &VerifyParams{AppID: "test_app", Nonce: nonce}); !errors.Is(err, ErrInvalidRequest) {
+			t.Errorf("期望无效请求错误: %q, 实际: %v", nonce, err)
+		}
+	}
+	if err := sdk.verifyReplay(context.Background(), &VerifyParams{AppID: "test_app", Nonce: strings.Repeat("n", MaxNonceLength)}); err != nil {
+		t.Errorf("最大长度的nonce登记失败: %v", err)
+	}
+
+	// 超过保留时长后nonce被清理
+	now = now.Add(sdk.nonceTTL() + memoryNonceSweepInterval)
+	if err := sdk.verifyReplay(context.Background(), params); err != nil {
+		t.Errorf("nonce过期后再次使用失败: %v", err)
+	}
+	if store.Len() != 1 {
+		t.Errorf("过期nonce未清理: %d", store.Len())
+	}
+}
+
+// copyData 复制签名参数
+func copyData(data map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		result[k] = v
+	}
+	return result
+}
+
 // TestSDKGenerateSign 测试SDK签名生成
 func TestSDKGenerateSign(t *testing.T) {
 	sdk, db := createTestSDK(t)
@@ -394,6 +1159,152 @@ func TestSDKVerifySign(t *testing.T) {
 	}
 }
 
+// TestSDKSignTypeMigration 测试应用签名算法迁移
+func TestSDKSignTypeMigration(t *testing.T) {
+	sdk, db := createTestSDK(t)
+	defer teardownTestDB(t, db)
+
+	appID := "test_app_sign_type"
+	secretKey := "test_secret_sign_type"
+	if err := sdk.CreateAppKey(appID, secretKey, []string{}, map[string]interface{}{}); err != nil {
+		t.Fatalf("创建测试应用失败: %v", err)
+	}
+
+	// 迁移期间同时接受HMAC-SHA256和MD5
+	if err := sdk.SetSignTypes(appID, SignTypeHMACSHA256, []SignType{SignTypeMD5}); err != nil {
+		t.Fatalf("设置签名算法失败: %v", err)
+	}
+
+	md5Sign, _ := GenerateSign(map[string]interface{}{"nonce": "n1"}, secretKey)
+	err := sdk.VerifySign(&VerifyParams{
+		AppID:    appID,
+		Data:     map[string]interface{}{"nonce": "n1", "sign": md5Sign},
+		ClientIP: "127.0.0.1",
+	})
+	if err != nil {
+		t.Fatalf("MD5签名验证失败: %v", err)
+	}
+	if sdk.SignTypeUsage(appID)[SignTypeMD5].Count != 1 {
+		t.Errorf("MD5使用统计错误: %v", sdk.SignTypeUsage(appID))
+	}
+
+	// 切换为仅HMAC-SHA256
+	if err := sdk.SetSignTypes(appID, SignTypeHMACSHA256, nil); err != nil {
+		t.Fatalf("设置签名算法失败: %v", err)
+	}
+	err = sdk.VerifySign(&VerifyParams{
+		AppID:    appID,
+		Data:     map[string]interface{}{"nonce": "n1", "sign": md5Sign},
+		ClientIP: "127.0.0.1",
+	})
+	if err != ErrInvalidSign {
+		t.Errorf("期望签名验证失败, 实际: %v", err)
+	}
+}
+
+// TestCacheInvalidationNotify 测试通过 LISTEN/NOTIFY 清除其他实例的缓存
+func TestCacheInvalidationNotify(t *testing.T) {
+	writer, db := createTestSDK(t)
+	defer teardownTestDB(t, db)
+
+	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
+		testDBHost, testDBPort, testDBUser, testDBPassword, testDBName)
+	reader := NewSignatureSDK(&Config{DB: db, Cache: &CacheConfig{TTL: time.Hour}, ListenDSN: dsn})
+	defer reader.Close()
+
+	appID := "test_app_notify"
+	if err := writer.CreateAppKey(appID, "old_secret", []string{}, nil); err != nil {
+		t.Fatalf("创建测试应用失败: %v", err)
+	}
+	if _, err := reader.GetAppKey(appID); err != nil {
+		t.Fatalf("获取应用失败: %v", err)
+	}
+
+	if err := writer.UpdateAppKey(appID, "new_secret", []string{}, 1, nil); err != nil {
+		t.Fatalf("更新应用失败: %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		appKey, err := reader.GetAppKey(appID)
+		if err == nil && appKey.SecretKey == "new_secret" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("1秒内未收到变更通知")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestMigrations 测试内置迁移的顺序
+func TestMigrations(t *testing.T) {
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatalf("读取迁移失败: %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("没有内置迁移")
+	}
+	for i, m := range migrations {
+		if m.Name == "" || m.SQL == "" {
+			t.Errorf("迁移 %d 内容为空", m.Version)
+		}
+		if i > 0 && m.Version <= migrations[i-1].Version {
+			t.Errorf("迁移顺序错误: %d 在 %d 之后", m.Version, migrations[i-1].Version)
+		}
+	}
+}
+
+// TestMigrate 测试数据库迁移可重复执行
+func TestMigrate(t *testing.T) {
+	sdk, db := createTestSDK(t)
+	defer teardownTestDB(t, db)
+
+	// 模拟旧版本创建的表，缺少后来新增的列
+	if _, err := db.Exec(dropTestTables); err != nil {
+		t.Fatalf("清理测试表失败: %v", err)
+	}
+	_, err := db.Exec(`CREATE TABLE app_keys (
+    id SERIAL PRIMARY KEY,
+    app_id VARCHAR(32) NOT NULL UNIQUE,
+    secret_key VARCHAR(64) NOT NULL,
+    ips_white JSONB NOT NULL,
+    status SMALLINT NOT NULL DEFAULT 1,
+    create_at BIGINT NOT NULL,
+    update_at BIGINT DEFAULT NULL)`)
+	if err != nil {
+		t.Fatalf("创建旧表失败: %v", err)
+	}
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if err := sdk.Migrate(ctx); err != nil {
+			t.Fatalf("第%d次迁移失败: %v", i+1, err)
+		}
+	}
+
+	migrations, _ := Migrations()
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM schema_migrations`).Scan(&count); err != nil {
+		t.Fatalf("查询迁移记录失败: %v", err)
+	}
+	if count != len(migrations) {
+		t.Errorf("期望 %d 条迁移记录, 实际 %d", len(migrations), count)
+	}
+
+	if err := sdk.CreateAppKey("test_app_migrate", "test_secret", []string{}, map[string]interface{}{"k": "v"}); err != nil {
+		t.Fatalf("迁移后创建应用失败: %v", err)
+	}
+	appKey, err := sdk.GetAppKey("test_app_migrate")
+	if err != nil {
+		t.Fatalf("迁移后获取应用失败: %v", err)
+	}
+	if appKey.Attributes["k"] != "v" || appKey.SignType != SignTypeMD5 {
+		t.Errorf("迁移后应用数据错误: %+v", appKey)
+	}
+}
+
 // TestIPWhitelist 测试IP白名单验证
 func TestIPWhitelist(t *testing.T) {
 	sdk, db := createTestSDK(t)
@@ -433,7 +1344,7 @@ func TestIPWhitelist(t *testing.T) {
 			name:      "无效IP",
 			clientIP:  "invalid_ip",
 			whitelist: []string{"127.0.0.1"},
-			expectErr: nil, // 这个会返回格式错误，不是ErrIPNotAllowed
+			expectErr: ErrIPNotAllowed,
 		},
 	}
 
@@ -441,10 +1352,10 @@ func TestIPWhitelist(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			err := sdk.verifyIPWhitelist(tc.clientIP, tc.whitelist)
 			if tc.expectErr != nil {
-				if err != tc.expectErr {
+				if !errors.Is(err, tc.expectErr) {
 					t.Errorf("期望错误 %v, 实际错误 %v", tc.expectErr, err)
 				}
-			} else if err != nil && tc.clientIP != "invalid_ip" {
+			} else if err != nil {
 				t.Errorf("意外错误: %v", err)
 			}
 		})
@@ -0,0 +1,141 @@
+package go_signature_sdk
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// AppSecret 应用的对称密钥，一个应用可以同时有多个有效密钥，用于不停机轮换
+type AppSecret struct {
+	KeyID     string `json:"key_id"`
+	Secret    string `json:"secret"`
+	Primary   bool   `json:"primary"`    // 主密钥用于生成签名，同步保存在 AppKey.SecretKey
+	NotBefore int64  `json:"not_before"` // 生效时间戳，0表示立即生效
+	NotAfter  int64  `json:"not_after"`  // 失效时间戳，0表示永不失效
+	CreateAt  int64  `json:"create_at"`
+}
+
+// ValidAt 判断密钥在指定时间是否有效
+func (s AppSecret) ValidAt(t time.Time) bool {
+	now := t.Unix()
+	if s.NotBefore != 0 && now < s.NotBefore {
+		return false
+	}
+	if s.NotAfter != 0 && now >= s.NotAfter {
+		return false
+	}
+	return true
+}
+
+// verifyKey 验签候选密钥
+type verifyKey struct {
+	ID  string
+	Key string
+}
+
+// newKeyID 生成随机的密钥ID
+func newKeyID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("生成密钥ID失败: %v", err))
+	}
+	return "k" + hex.EncodeToString(b)
+}
+
+// primarySecret 返回应用的主密钥，没有时返回nil
+func (a *AppKey) primarySecret() *AppSecret {
+	for i := range a.Secrets {
+		if a.Secrets[i].Primary {
+			return &a.Secrets[i]
+		}
+	}
+	return nil
+}
+
+// normalizeSecrets 为新应用整理密钥：没有密钥时以 SecretKey 作为主密钥，
+// 补齐密钥ID和创建时间，并保证 SecretKey 与主密钥一致
+func normalizeSecrets(appKey *AppKey) error {
+	if len(appKey.Secrets) == 0 {
+		appKey.Secrets = []AppSecret{{Secret: appKey.SecretKey, Primary: true}}
+	}
+
+	var primary int
+	for i := range appKey.Secrets {
+		secret := &appKey.Secrets[i]
+		if secret.KeyID == "" {
+			secret.KeyID = newKeyID()
+		}
+		if secret.CreateAt == 0 {
+			secret.CreateAt = appKey.CreateAt
+		}
+		if secret.Primary {
+			primary++
+			appKey.SecretKey = secret.Secret
+		}
+	}
+	if primary != 1 {
+		return fmt.Errorf("%w: 应用必须有且只有一个主密钥", ErrInvalidKey)
+	}
+	return nil
+}
+
+// verifyKeys 返回验签时依次尝试的密钥
+// 非对称算法使用公钥；对称算法使用当前有效的全部密钥，主密钥优先
+func (a *AppKey) verifyKeys(signType SignType, now time.Time) []verifyKey {
+	if IsAsymmetric(signType) {
+		return []verifyKey{{Key: a.PublicKey}}
+	}
+	if len(a.Secrets) == 0 {
+		return []verifyKey{{Key: a.SecretKey}}
+	}
+
+	keys := make([]verifyKey, 0, len(a.Secrets))
+	for _, secret := range a.Secrets {
+		if !secret.ValidAt(now) {
+			continue
+		}
+		key := verifyKey{ID: secret.KeyID, Key: secret.Secret}
+		if secret.Primary {
+			keys = append([]verifyKey{key}, keys...)
+		} else {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// AddSecret 为应用添加密钥，KeyID 为空时自动生成
+// Primary 为true时新密钥成为主密钥，原主密钥继续用于验签直到被删除或过期
+func (s *SignatureSDK) AddSecret(ctx context.Context, appID string, secret *AppSecret) error {
+	if secret.Secret == "" {
+		return fmt.Errorf("%w: 密钥不能为空", ErrInvalidKey)
+	}
+	if err := s.policy.ValidateSecret(secret.Secret); err != nil {
+		return err
+	}
+	if secret.KeyID == "" {
+		secret.KeyID = newKeyID()
+	}
+	if secret.CreateAt == 0 {
+		secret.CreateAt = s.now().Unix()
+	}
+	return s.store.AddSecret(ctx, appID, secret)
+}
+
+// RemoveSecret 删除应用的密钥，不能删除主密钥
+func (s *SignatureSDK) RemoveSecret(ctx context.Context, appID, keyID string) error {
+	return s.store.RemoveSecret(ctx, appID, keyID)
+}
+
+// SetPrimarySecret 将应用的某个密钥设为主密钥
+func (s *SignatureSDK) SetPrimarySecret(ctx context.Context, appID, keyID string) error {
+	return s.store.SetPrimarySecret(ctx, appID, keyID)
+}
+
+// SecretUsage 返回本实例中应用各密钥的验签成功次数，用于判断旧密钥是否仍在使用，多实例汇总见 StartUsageExporter
+func (s *SignatureSDK) SecretUsage(appID string) map[string]UsageStat {
+	return s.secretUsage.snapshot(appID)
+}
@@ -5,20 +5,97 @@ import (
 	"strings"
 )
 
-// GenerateSign 生成签名
+// GenerateSign 生成签名（MD5），第二个返回值为脱敏后的签名字符串
+//
+// Deprecated: 使用 Sign。
 func GenerateSign(data map[string]interface{}, secretKey string) (string, string) {
-	signStr := buildSignString(data, secretKey)
-	return md5Hash(signStr), strings.ReplaceAll(signStr, secretKey, "***SECRET***")
+	sign, signStr, _ := GenerateSignWith(SignTypeMD5, data, secretKey)
+	return sign, signStr
 }
 
-// VerifySign 验证签名
+// GenerateSignWith 使用指定算法生成签名，返回签名和脱敏后的签名字符串
+func GenerateSignWith(signType SignType, data map[string]interface{}, key string) (string, string, error) {
+	signer, err := GetSigner(signType)
+	if err != nil {
+		return "", "", err
+	}
+
+	content := buildCanonicalString(data)
+	sign, err := signer.Sign(content, key)
+	if err != nil {
+		return "", "", err
+	}
+	return sign, maskSignString(signer.Type(), data, content, key), nil
+}
+
+// VerifySign 验证签名（MD5）
+//
+// Deprecated: 使用 Verify。
 func VerifySign(params *VerifyParams, secretKey string) error {
-	sign := params.Data["sign"]
-	params.Data["sign"] = ""
-	generateSign, s := GenerateSign(params.Data, secretKey)
-	if generateSign != sign {
-		log.Println("签名验证失败:", generateSign, s)
-		return ErrInvalidSign
-	}
-	return nil
+	return VerifySignWith(params, SignTypeMD5, secretKey)
+}
+
+// VerifySignWith 使用指定算法验证签名，非对称算法的 key 为PEM公钥
+// 与旧版本一致，签名不一致时通过标准库 log 输出脱敏的签名字符串
+//
+// Deprecated: 使用 Verify 和 WithAlgorithm。
+func VerifySignWith(params *VerifyParams, signType SignType, key string) error {
+	sign := params.Sign
+	if sign == "" {
+		sign, _ = params.Data[DefaultSignField].(string)
+	}
+	_, _, err := verifyAnySignType(withoutField(params.Data, DefaultSignField), sign, []SignType{signType}, func(SignType) []verifyKey {
+		return []verifyKey{{Key: key}}
+	}, log.Default())
+	return err
+}
+
+// verifyAnySignType 依次使用候选算法和密钥验证签名，返回匹配的算法和密钥ID
+// data 不含签名字段；keysFor 返回各算法验签使用的密钥，有多个候选算法时跳过空密钥；logger 为空时不输出日志
+func verifyAnySignType(data map[string]interface{}, sign string, signTypes []SignType, keysFor func(SignType) []verifyKey, logger Logger) (SignType, string, error) {
+	signers := make([]Signer, 0, len(signTypes))
+	for _, signType := range signTypes {
+		signer, err := GetSigner(signType)
+		if err != nil {
+			return "", "", err
+		}
+		signers = append(signers, signer)
+	}
+	if len(signers) == 0 {
+		return "", "", ErrUnsupportedSignType
+	}
+
+	content := buildCanonicalString(data)
+	var logKey string
+	for i, signer := range signers {
+		keys := keysFor(signer.Type())
+		if i == 0 && len(keys) > 0 {
+			logKey = keys[0].Key
+		}
+		for _, key := range keys {
+			if key.Key == "" && len(signers) > 1 {
+				continue
+			}
+			if err := signer.Verify(content, key.Key, sign); err == nil {
+				return signer.Type(), key.ID, nil
+			}
+		}
+	}
+
+	if logger != nil {
+		logger.Printf("签名验证失败: %v %s", signTypes, maskSignString(signers[0].Type(), data, content, logKey))
+	}
+	return "", "", ErrInvalidSign
+}
+
+// maskSignString 返回用于日志的签名字符串，MD5/SM3签名字符串中的密钥会被替换
+func maskSignString(signType SignType, data map[string]interface{}, content, key string) string {
+	if signType != SignTypeMD5 && signType != SignTypeSM3 {
+		return content
+	}
+	signStr := buildSignString(data, key)
+	if key == "" {
+		return signStr
+	}
+	return strings.ReplaceAll(signStr, key, "***SECRET***")
 }
@@ -0,0 +1,138 @@
+package go_signature_sdk
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"strings"
+	"sync"
+)
+
+// SignType 签名算法类型
+type SignType string
+
+// 内置签名算法
+const (
+	SignTypeMD5        SignType = "MD5"
+	SignTypeHMACSHA256 SignType = "HMAC-SHA256"
+	SignTypeHMACSHA512 SignType = "HMAC-SHA512"
+)
+
+// Signer 签名算法
+// content 为 buildCanonicalString 生成的规范化字符串，key 为算法使用的密钥
+type Signer interface {
+	// Type 返回算法类型
+	Type() SignType
+	// Sign 生成签名
+	Sign(content, key string) (string, error)
+	// Verify 验证签名，不匹配时返回 ErrInvalidSign
+	Verify(content, key, sign string) error
+}
+
+var (
+	signersMu sync.RWMutex
+	signers   = map[SignType]Signer{}
+)
+
+func init() {
+	RegisterSigner(md5Signer{})
+	RegisterSigner(&hmacSigner{signType: SignTypeHMACSHA256, newHash: sha256.New})
+	RegisterSigner(&hmacSigner{signType: SignTypeHMACSHA512, newHash: sha512.New})
+}
+
+// RegisterSigner 注册签名算法，同类型的算法会被覆盖
+func RegisterSigner(signer Signer) {
+	signersMu.Lock()
+	defer signersMu.Unlock()
+	signers[signer.Type()] = signer
+}
+
+// GetSigner 获取签名算法，signType 为空时使用MD5
+func GetSigner(signType SignType) (Signer, error) {
+	if signType == "" {
+		signType = SignTypeMD5
+	}
+
+	signersMu.RLock()
+	defer signersMu.RUnlock()
+	signer, ok := signers[signType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedSignType, signType)
+	}
+	return signer, nil
+}
+
+// md5Signer MD5签名，密钥以 &key= 的形式拼接在规范化字符串末尾
+type md5Signer struct{}
+
+func (md5Signer) Type() SignType {
+	return SignTypeMD5
+}
+
+func (md5Signer) Sign(content, key string) (string, error) {
+	return md5Hash(content + "&key=" + key), nil
+}
+
+func (m md5Signer) Verify(content, key, sign string) error {
+	expected, _ := m.Sign(content, key)
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(sign)) != 1 {
+		return ErrInvalidSign
+	}
+	return nil
+}
+
+// hmacSigner HMAC签名，结果为大写十六进制
+type hmacSigner struct {
+	signType SignType
+	newHash  func() hash.Hash
+}
+
+func (h *hmacSigner) Type() SignType {
+	return h.signType
+}
+
+func (h *hmacSigner) Sign(content, key string) (string, error) {
+	mac := hmac.New(h.newHash, []byte(key))
+	mac.Write([]byte(content))
+	return strings.ToUpper(hex.EncodeToString(mac.Sum(nil))), nil
+}
+
+func (h *hmacSigner) Verify(content, key, sign string) error {
+	expected, _ := h.Sign(content, key)
+	// 十六进制签名不区分大小写
+	if !hmac.Equal([]byte(expected), []byte(strings.ToUpper(sign))) {
+		return ErrInvalidSign
+	}
+	return nil
+}
+
+// acceptedSignTypes 返回应用验签时接受的算法
+// 请求参数中携带 sign_type 时只使用该算法，且必须在允许列表内
+func (a *AppKey) acceptedSignTypes(data map[string]interface{}) ([]SignType, error) {
+	primary := a.SignType
+	if primary == "" {
+		primary = SignTypeMD5
+	}
+
+	allowed := []SignType{primary}
+	for _, t := range a.AllowedSignTypes {
+		if t != primary {
+			allowed = append(allowed, t)
+		}
+	}
+
+	requested, _ := data["sign_type"].(string)
+	if requested == "" {
+		return allowed, nil
+	}
+	for _, t := range allowed {
+		if t == SignType(requested) {
+			return []SignType{t}, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrSignTypeNotAllowed, requested)
+}
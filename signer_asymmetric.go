@@ -0,0 +1,254 @@
+package go_signature_sdk
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// 内置非对称签名算法，签名结果为标准Base64编码
+const (
+	SignTypeRSA2      SignType = "RSA2"       // RSA PKCS#1 v1.5 + SHA256
+	SignTypeRSAPSS    SignType = "RSA-PSS"    // RSA-PSS + SHA256
+	SignTypeECDSAP256 SignType = "ECDSA-P256" // ECDSA P-256 + SHA256，ASN.1 DER
+	SignTypeEd25519   SignType = "Ed25519"
+)
+
+// AsymmetricSigner 非对称签名算法
+// Sign 的 key 为PEM格式私钥，Verify 的 key 为PEM格式公钥
+type AsymmetricSigner interface {
+	Signer
+	Asymmetric() bool
+}
+
+func init() {
+	RegisterSigner(&rsaSigner{signType: SignTypeRSA2})
+	RegisterSigner(&rsaSigner{signType: SignTypeRSAPSS, pss: true})
+	RegisterSigner(ecdsaSigner{})
+	RegisterSigner(ed25519Signer{})
+}
+
+// IsAsymmetric 判断签名算法是否为非对称算法
+func IsAsymmetric(signType SignType) bool {
+	signer, err := GetSigner(signType)
+	if err != nil {
+		return false
+	}
+	a, ok := signer.(AsymmetricSigner)
+	return ok && a.Asymmetric()
+}
+
+// rsaSigner RSA签名
+type rsaSigner struct {
+	signType SignType
+	pss      bool
+}
+
+func (r *rsaSigner) Type() SignType {
+	return r.signType
+}
+
+func (r *rsaSigner) Asymmetric() bool {
+	return true
+}
+
+func (r *rsaSigner) Sign(content, key string) (string, error) {
+	privateKey, err := parsePrivateKey(key)
+	if err != nil {
+		return "", err
+	}
+	rsaKey, ok := privateKey.(*rsa.PrivateKey)
+	if !ok {
+		return "", fmt.Errorf("%w: 需要RSA私钥", ErrInvalidKey)
+	}
+
+	digest := sha256.Sum256([]byte(content))
+	var sig []byte
+	if r.pss {
+		sig, err = rsa.SignPSS(rand.Reader, rsaKey, crypto.SHA256, digest[:], &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+	} else {
+		sig, err = rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+	}
+	if err != nil {
+		return "", fmt.Errorf("RSA签名失败: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(sig), nil
+}
+
+func (r *rsaSigner) Verify(content, key, sign string) error {
+	publicKey, err := parsePublicKey(key)
+	if err != nil {
+		return err
+	}
+	rsaKey, ok := publicKey.(*rsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("%w: 需要RSA公钥", ErrInvalidKey)
+	}
+	sig, err := base64.StdEncoding.DecodeString(sign)
+	if err != nil {
+		return ErrInvalidSign
+	}
+
+	digest := sha256.Sum256([]byte(content))
+	if r.pss {
+		err = rsa.VerifyPSS(rsaKey, crypto.SHA256, digest[:], sig, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthAuto})
+	} else {
+		err = rsa.VerifyPKCS1v15(rsaKey, crypto.SHA256, digest[:], sig)
+	}
+	if err != nil {
+		return ErrInvalidSign
+	}
+	return nil
+}
+
+// ecdsaSigner ECDSA P-256签名
+type ecdsaSigner struct{}
+
+func (ecdsaSigner) Type() SignType {
+	return SignTypeECDSAP256
+}
+
+func (ecdsaSigner) Asymmetric() bool {
+	return true
+}
+
+func (ecdsaSigner) Sign(content, key string) (string, error) {
+	privateKey, err := parsePrivateKey(key)
+	if err != nil {
+		return "", err
+	}
+	ecKey, ok := privateKey.(*ecdsa.PrivateKey)
+	if !ok || ecKey.Curve != elliptic.P256() {
+		return "", fmt.Errorf("%w: 需要P-256私钥", ErrInvalidKey)
+	}
+
+	digest := sha256.Sum256([]byte(content))
+	sig, err := ecdsa.SignASN1(rand.Reader, ecKey, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("ECDSA签名失败: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(sig), nil
+}
+
+func (ecdsaSigner) Verify(content, key, sign string) error {
+	publicKey, err := parsePublicKey(key)
+	if err != nil {
+		return err
+	}
+	ecKey, ok := publicKey.(*ecdsa.PublicKey)
+	if !ok || ecKey.Curve != elliptic.P256() {
+		return fmt.Errorf("%w: 需要P-256公钥", ErrInvalidKey)
+	}
+	sig, err := base64.StdEncoding.DecodeString(sign)
+	if err != nil {
+		return ErrInvalidSign
+	}
+
+	digest := sha256.Sum256([]byte(content))
+	if !ecdsa.VerifyASN1(ecKey, digest[:], sig) {
+		return ErrInvalidSign
+	}
+	return nil
+}
+
+// ed25519Signer Ed25519签名
+type ed25519Signer struct{}
+
+func (ed25519Signer) Type() SignType {
+	return SignTypeEd25519
+}
+
+func (ed25519Signer) Asymmetric() bool {
+	return true
+}
+
+func (ed25519Signer) Sign(content, key string) (string, error) {
+	privateKey, err := parsePrivateKey(key)
+	if err != nil {
+		return "", err
+	}
+	edKey, ok := privateKey.(ed25519.PrivateKey)
+	if !ok {
+		return "", fmt.Errorf("%w: 需要Ed25519私钥", ErrInvalidKey)
+	}
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(edKey, []byte(content))), nil
+}
+
+func (ed25519Signer) Verify(content, key, sign string) error {
+	publicKey, err := parsePublicKey(key)
+	if err != nil {
+		return err
+	}
+	edKey, ok := publicKey.(ed25519.PublicKey)
+	if !ok {
+		return fmt.Errorf("%w: 需要Ed25519公钥", ErrInvalidKey)
+	}
+	sig, err := base64.StdEncoding.DecodeString(sign)
+	if err != nil {
+		return ErrInvalidSign
+	}
+	if !ed25519.Verify(edKey, []byte(content), sig) {
+		return ErrInvalidSign
+	}
+	return nil
+}
+
+// parsePrivateKey 解析PEM私钥，支持PKCS#8、PKCS#1(RSA)和SEC1(EC)
+func parsePrivateKey(key string) (interface{}, error) {
+	block, _ := pem.Decode([]byte(key))
+	if block == nil {
+		return nil, fmt.Errorf("%w: 私钥不是PEM格式", ErrInvalidKey)
+	}
+
+	if privateKey, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		return privateKey, nil
+	}
+	if privateKey, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return privateKey, nil
+	}
+	if privateKey, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return privateKey, nil
+	}
+	return nil, fmt.Errorf("%w: 无法解析私钥", ErrInvalidKey)
+}
+
+// parsePublicKey 解析PEM公钥，支持PKIX、PKCS#1(RSA)和X.509证书
+func parsePublicKey(key string) (interface{}, error) {
+	block, _ := pem.Decode([]byte(key))
+	if block == nil {
+		return nil, fmt.Errorf("%w: 公钥不是PEM格式", ErrInvalidKey)
+	}
+
+	if publicKey, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		return publicKey, nil
+	}
+	if publicKey, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return publicKey, nil
+	}
+	if cert, err := x509.ParseCertificate(block.Bytes); err == nil {
+		return cert.PublicKey, nil
+	}
+	return nil, fmt.Errorf("%w: 无法解析公钥", ErrInvalidKey)
+}
+
+// ValidatePublicKey 检查公钥能否用于指定的签名算法
+func ValidatePublicKey(signType SignType, publicKey string) error {
+	if !IsAsymmetric(signType) {
+		return fmt.Errorf("%w: %s 不是非对称算法", ErrUnsupportedSignType, signType)
+	}
+	signer, _ := GetSigner(signType)
+	// 用空签名试验证，密钥类型不匹配时返回 ErrInvalidKey
+	if err := signer.Verify("", publicKey, ""); err != nil && !errors.Is(err, ErrInvalidSign) {
+		return err
+	}
+	return nil
+}
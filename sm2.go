@@ -0,0 +1,497 @@
+package go_signature_sdk
+
+import (
+	"crypto/rand"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// SM2 数字签名算法（GB/T 32918.2-2016），杂凑算法为SM3
+// 涉及私钥和签名随机数的运算（生成密钥、计算公钥、签名）使用 sm2_const.go 的常量时间实现；
+// 验签只处理公开数据，使用本文件基于 math/big 的实现
+
+// SM2DefaultUID SM2签名默认用户身份标识
+const SM2DefaultUID = "1234567812345678"
+
+// sm2Curve 素域椭圆曲线 y^2 = x^3 + ax + b
+type sm2Curve struct {
+	P, A, B, N, Gx, Gy *big.Int
+}
+
+func mustHex(s string) *big.Int {
+	v, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		panic("invalid hex: " + s)
+	}
+	return v
+}
+
+// sm2P256 SM2推荐曲线参数
+var sm2P256 = &sm2Curve{
+	P:  mustHex("FFFFFFFEFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF00000000FFFFFFFFFFFFFFFF"),
+	A:  mustHex("FFFFFFFEFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF00000000FFFFFFFFFFFFFFFC"),
+	B:  mustHex("28E9FA9E9D9F5E344D5A9E4BCF6509A7F39789F515AB8F92DDBCBD414D940E93"),
+	N:  mustHex("FFFFFFFEFFFFFFFFFFFFFFFFFFFFFFFF7203DF6B21C6052B53BBF40939D54123"),
+	Gx: mustHex("32C4AE2C1F1981195F9904466A39C9948FE30BBFF2660BE1715A4589334C74C7"),
+	Gy: mustHex("BC3736A2F4F6779C59BDCEE36B692153D0A9877CC62A474002DF32E52139F0A0"),
+}
+
+var (
+	oidSM2           = asn1.ObjectIdentifier{1, 2, 156, 10197, 1, 301}
+	oidECPublicKey   = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	bigOne           = big.NewInt(1)
+	errSM2NotOnCurve = errors.New("SM2公钥不在曲线上")
+)
+
+// SM2PublicKey SM2公钥
+type SM2PublicKey struct {
+	X, Y *big.Int
+}
+
+// SM2PrivateKey SM2私钥
+type SM2PrivateKey struct {
+	SM2PublicKey
+	D *big.Int
+}
+
+// GenerateSM2Key 生成SM2密钥对，私钥 d ∈ [1, n-2]
+func GenerateSM2Key(random io.Reader) (*SM2PrivateKey, error) {
+	if random == nil {
+		random = rand.Reader
+	}
+	max, _ := subLimbs(&sm2P256Const.n.m, &sm2Limbs{1})
+	d, err := sm2P256Const.randomScalar(random, &max)
+	if err != nil {
+		return nil, err
+	}
+	return newSM2PrivateKey(&d), nil
+}
+
+// newSM2PrivateKey 由大端序的私钥 d 计算公钥
+func newSM2PrivateKey(d *[32]byte) *SM2PrivateKey {
+	x, y := sm2P256Const.scalarBaseMult(d)
+	return &SM2PrivateKey{
+		SM2PublicKey: SM2PublicKey{X: new(big.Int).SetBytes(x[:]), Y: new(big.Int).SetBytes(y[:])},
+		D:            new(big.Int).SetBytes(d[:]),
+	}
+}
+
+// isOnCurve 判断点是否在曲线上
+func (c *sm2Curve) isOnCurve(x, y *big.Int) bool {
+	if x.Sign() < 0 || x.Cmp(c.P) >= 0 || y.Sign() < 0 || y.Cmp(c.P) >= 0 {
+		return false
+	}
+	y2 := new(big.Int).Mul(y, y)
+	y2.Mod(y2, c.P)
+
+	rhs := new(big.Int).Mul(x, x)
+	rhs.Add(rhs, c.A)
+	rhs.Mul(rhs, x)
+	rhs.Add(rhs, c.B)
+	rhs.Mod(rhs, c.P)
+	return y2.Cmp(rhs) == 0
+}
+
+// jacobianPoint 雅可比坐标点，Z 为0表示无穷远点
+type jacobianPoint struct {
+	x, y, z *big.Int
+}
+
+func (c *sm2Curve) toJacobian(x, y *big.Int) *jacobianPoint {
+	return &jacobianPoint{x: new(big.Int).Set(x), y: new(big.Int).Set(y), z: big.NewInt(1)}
+}
+
+func (c *sm2Curve) toAffine(p *jacobianPoint) (*big.Int, *big.Int) {
+	if p.z.Sign() == 0 {
+		return new(big.Int), new(big.Int)
+	}
+	zInv := new(big.Int).ModInverse(p.z, c.P)
+	zInv2 := new(big.Int).Mul(zInv, zInv)
+	x := new(big.Int).Mul(p.x, zInv2)
+	x.Mod(x, c.P)
+	zInv2.Mul(zInv2, zInv)
+	y := new(big.Int).Mul(p.y, zInv2)
+	y.Mod(y, c.P)
+	return x, y
+}
+
+// double 倍点
+func (c *sm2Curve) double(p *jacobianPoint) *jacobianPoint {
+	if p.z.Sign() == 0 || p.y.Sign() == 0 {
+		return &jacobianPoint{x: new(big.Int), y: new(big.Int), z: new(big.Int)}
+	}
+	xx := new(big.Int).Mul(p.x, p.x)
+	yy := new(big.Int).Mul(p.y, p.y)
+	yy.Mod(yy, c.P)
+	yyyy := new(big.Int).Mul(yy, yy)
+	zz := new(big.Int).Mul(p.z, p.z)
+	zz.Mod(zz, c.P)
+
+	// S = 4*X*YY
+	s := new(big.Int).Mul(p.x, yy)
+	s.Lsh(s, 2)
+	s.Mod(s, c.P)
+
+	// M = 3*XX + a*ZZ^2
+	m := new(big.Int).Mul(xx, big.NewInt(3))
+	t := new(big.Int).Mul(zz, zz)
+	t.Mul(t, c.A)
+	m.Add(m, t)
+	m.Mod(m, c.P)
+
+	// X3 = M^2 - 2*S
+	x3 := new(big.Int).Mul(m, m)
+	x3.Sub(x3, new(big.Int).Lsh(s, 1))
+	x3.Mod(x3, c.P)
+
+	// Y3 = M*(S - X3) - 8*YYYY
+	y3 := new(big.Int).Sub(s, x3)
+	y3.Mul(y3, m)
+	y3.Sub(y3, yyyy.Lsh(yyyy, 3))
+	y3.Mod(y3, c.P)
+
+	// Z3 = 2*Y*Z
+	z3 := new(big.Int).Mul(p.y, p.z)
+	z3.Lsh(z3, 1)
+	z3.Mod(z3, c.P)
+
+	return &jacobianPoint{x: x3, y: y3, z: z3}
+}
+
+// add 点加
+func (c *sm2Curve) add(p, q *jacobianPoint) *jacobianPoint {
+	if p.z.Sign() == 0 {
+		return q
+	}
+	if q.z.Sign() == 0 {
+		return p
+	}
+
+	z1z1 := new(big.Int).Mul(p.z, p.z)
+	z1z1.Mod(z1z1, c.P)
+	z2z2 := new(big.Int).Mul(q.z, q.z)
+	z2z2.Mod(z2z2, c.P)
+
+	u1 := new(big.Int).Mul(p.x, z2z2)
+	u1.Mod(u1, c.P)
+	u2 := new(big.Int).Mul(q.x, z1z1)
+	u2.Mod(u2, c.P)
+
+	s1 := new(big.Int).Mul(p.y, q.z)
+	s1.Mul(s1, z2z2)
+	s1.Mod(s1, c.P)
+	s2 := new(big.Int).Mul(q.y, p.z)
+	s2.Mul(s2, z1z1)
+	s2.Mod(s2, c.P)
+
+	if u1.Cmp(u2) == 0 {
+		if s1.Cmp(s2) != 0 {
+			return &jacobianPoint{x: new(big.Int), y: new(big.Int), z: new(big.Int)}
+		}
+		return c.double(p)
+	}
+
+	h := new(big.Int).Sub(u2, u1)
+	r := new(big.Int).Sub(s2, s1)
+	hh := new(big.Int).Mul(h, h)
+	hh.Mod(hh, c.P)
+	hhh := new(big.Int).Mul(hh, h)
+	hhh.Mod(hhh, c.P)
+	v := new(big.Int).Mul(u1, hh)
+	v.Mod(v, c.P)
+
+	// X3 = R^2 - H^3 - 2*U1*H^2
+	x3 := new(big.Int).Mul(r, r)
+	x3.Sub(x3, hhh)
+	x3.Sub(x3, new(big.Int).Lsh(v, 1))
+	x3.Mod(x3, c.P)
+
+	// Y3 = R*(U1*H^2 - X3) - S1*H^3
+	y3 := new(big.Int).Sub(v, x3)
+	y3.Mul(y3, r)
+	y3.Sub(y3, s1.Mul(s1, hhh))
+	y3.Mod(y3, c.P)
+
+	// Z3 = H*Z1*Z2
+	z3 := new(big.Int).Mul(h, p.z)
+	z3.Mul(z3, q.z)
+	z3.Mod(z3, c.P)
+
+	return &jacobianPoint{x: x3, y: y3, z: z3}
+}
+
+// scalarMult 计算 k*(x, y)，运行时间与 k 有关，k 不能是私钥或签名随机数
+func (c *sm2Curve) scalarMult(x, y, k *big.Int) (*big.Int, *big.Int) {
+	base := c.toJacobian(x, y)
+	result := &jacobianPoint{x: new(big.Int), y: new(big.Int), z: new(big.Int)}
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		result = c.double(result)
+		if k.Bit(i) == 1 {
+			result = c.add(result, base)
+		}
+	}
+	return c.toAffine(result)
+}
+
+func (c *sm2Curve) scalarBaseMult(k *big.Int) (*big.Int, *big.Int) {
+	return c.scalarMult(c.Gx, c.Gy, k)
+}
+
+// combinedMult 计算 s*G + t*(x, y)
+func (c *sm2Curve) combinedMult(x, y, s, t *big.Int) (*big.Int, *big.Int) {
+	x1, y1 := c.scalarBaseMult(s)
+	x2, y2 := c.scalarMult(x, y, t)
+	p := c.add(c.toJacobian(x1, y1), c.toJacobian(x2, y2))
+	return c.toAffine(p)
+}
+
+// fieldBytes 将域元素编码为定长大端字节
+func (c *sm2Curve) fieldBytes(v *big.Int) []byte {
+	return v.FillBytes(make([]byte, (c.P.BitLen()+7)/8))
+}
+
+// sm2Z 计算用户杂凑值 Z = SM3(ENTL || ID || a || b || xG || yG || xA || yA)
+func (c *sm2Curve) sm2Z(pub *SM2PublicKey, uid []byte) []byte {
+	h := NewSM3()
+	entl := len(uid) * 8
+	h.Write([]byte{byte(entl >> 8), byte(entl)})
+	h.Write(uid)
+	h.Write(c.fieldBytes(c.A))
+	h.Write(c.fieldBytes(c.B))
+	h.Write(c.fieldBytes(c.Gx))
+	h.Write(c.fieldBytes(c.Gy))
+	h.Write(c.fieldBytes(pub.X))
+	h.Write(c.fieldBytes(pub.Y))
+	return h.Sum(nil)
+}
+
+// digest 计算 e = SM3(Z || M)
+func (c *sm2Curve) digest(pub *SM2PublicKey, uid, msg []byte) *big.Int {
+	h := NewSM3()
+	h.Write(c.sm2Z(pub, uid))
+	h.Write(msg)
+	return new(big.Int).SetBytes(h.Sum(nil))
+}
+
+// verify SM2验签
+func (c *sm2Curve) verify(pub *SM2PublicKey, uid, msg []byte, r, s *big.Int) bool {
+	if r.Sign() <= 0 || r.Cmp(c.N) >= 0 || s.Sign() <= 0 || s.Cmp(c.N) >= 0 {
+		return false
+	}
+	e := c.digest(pub, uid, msg)
+
+	t := new(big.Int).Add(r, s)
+	t.Mod(t, c.N)
+	if t.Sign() == 0 {
+		return false
+	}
+
+	x1, _ := c.combinedMult(pub.X, pub.Y, s, t)
+	rr := new(big.Int).Add(e, x1)
+	rr.Mod(rr, c.N)
+	return rr.Cmp(r) == 0
+}
+
+// SM2Sign 使用SM2私钥签名，uid 为空时使用 SM2DefaultUID，返回ASN.1 DER编码的签名
+func SM2Sign(priv *SM2PrivateKey, uid, msg []byte) ([]byte, error) {
+	if len(uid) == 0 {
+		uid = []byte(SM2DefaultUID)
+	}
+	if priv.D == nil || priv.D.Sign() <= 0 || priv.D.Cmp(new(big.Int).Sub(sm2P256.N, bigOne)) >= 0 {
+		return nil, fmt.Errorf("SM2签名失败: %w: 私钥超出范围", ErrInvalidKey)
+	}
+	var d, e [32]byte
+	priv.D.FillBytes(d[:])
+	sm2P256.digest(&priv.SM2PublicKey, uid, msg).FillBytes(e[:])
+	for {
+		k, err := sm2P256Const.randomScalar(rand.Reader, &sm2P256Const.n.m)
+		if err != nil {
+			return nil, fmt.Errorf("SM2签名失败: %w", err)
+		}
+		if r, s, ok := sm2P256Const.signDigest(&d, &k, &e); ok {
+			return asn1.Marshal(sm2Signature{R: new(big.Int).SetBytes(r[:]), S: new(big.Int).SetBytes(s[:])})
+		}
+	}
+}
+
+// SM2Verify 使用SM2公钥验证ASN.1 DER编码的签名，uid 为空时使用 SM2DefaultUID
+func SM2Verify(pub *SM2PublicKey, uid, msg, sig []byte) bool {
+	if len(uid) == 0 {
+		uid = []byte(SM2DefaultUID)
+	}
+	var rs sm2Signature
+	rest, err := asn1.Unmarshal(sig, &rs)
+	if err != nil || len(rest) != 0 || rs.R == nil || rs.S == nil {
+		return false
+	}
+	return sm2P256.verify(pub, uid, msg, rs.R, rs.S)
+}
+
+type sm2Signature struct {
+	R, S *big.Int
+}
+
+// ASN.1 密钥结构
+type pkixPublicKey struct {
+	Algo      pkixAlgorithm
+	PublicKey asn1.BitString
+}
+
+type pkixAlgorithm struct {
+	Algorithm  asn1.ObjectIdentifier
+	Parameters asn1.ObjectIdentifier `asn1:"optional"`
+}
+
+type pkcs8PrivateKey struct {
+	Version    int
+	Algo       pkixAlgorithm
+	PrivateKey []byte
+}
+
+type ecPrivateKey struct {
+	Version       int
+	PrivateKey    []byte
+	NamedCurveOID asn1.ObjectIdentifier `asn1:"optional,explicit,tag:0"`
+	PublicKey     asn1.BitString        `asn1:"optional,explicit,tag:1"`
+}
+
+// marshalPoint 编码未压缩点 04 || X || Y
+func (pub *SM2PublicKey) marshalPoint() []byte {
+	return append([]byte{4}, append(sm2P256.fieldBytes(pub.X), sm2P256.fieldBytes(pub.Y)...)...)
+}
+
+// MarshalSM2PublicKeyPEM 将SM2公钥编码为PKIX PEM
+func MarshalSM2PublicKeyPEM(pub *SM2PublicKey) (string, error) {
+	point := pub.marshalPoint()
+	der, err := asn1.Marshal(pkixPublicKey{
+		Algo:      pkixAlgorithm{Algorithm: oidECPublicKey, Parameters: oidSM2},
+		PublicKey: asn1.BitString{Bytes: point, BitLength: len(point) * 8},
+	})
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})), nil
+}
+
+// MarshalSM2PrivateKeyPEM 将SM2私钥编码为PKCS#8 PEM
+func MarshalSM2PrivateKeyPEM(priv *SM2PrivateKey) (string, error) {
+	point := priv.marshalPoint()
+	ecDER, err := asn1.Marshal(ecPrivateKey{
+		Version:       1,
+		PrivateKey:    priv.D.FillBytes(make([]byte, 32)),
+		NamedCurveOID: oidSM2,
+		PublicKey:     asn1.BitString{Bytes: point, BitLength: len(point) * 8},
+	})
+	if err != nil {
+		return "", err
+	}
+	der, err := asn1.Marshal(pkcs8PrivateKey{
+		Algo:       pkixAlgorithm{Algorithm: oidECPublicKey, Parameters: oidSM2},
+		PrivateKey: ecDER,
+	})
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})), nil
+}
+
+// ParseSM2PublicKeyPEM 解析PKIX PEM格式的SM2公钥
+func ParseSM2PublicKeyPEM(key string) (*SM2PublicKey, error) {
+	block, _ := pem.Decode([]byte(key))
+	if block == nil {
+		return nil, fmt.Errorf("%w: 公钥不是PEM格式", ErrInvalidKey)
+	}
+	var pki pkixPublicKey
+	if _, err := asn1.Unmarshal(block.Bytes, &pki); err != nil {
+		return nil, fmt.Errorf("%w: 无法解析SM2公钥", ErrInvalidKey)
+	}
+	if !pki.Algo.Parameters.Equal(oidSM2) {
+		return nil, fmt.Errorf("%w: 不是SM2公钥", ErrInvalidKey)
+	}
+
+	point := pki.PublicKey.RightAlign()
+	if len(point) != 65 || point[0] != 4 {
+		return nil, fmt.Errorf("%w: 仅支持未压缩格式的SM2公钥", ErrInvalidKey)
+	}
+	pub := &SM2PublicKey{
+		X: new(big.Int).SetBytes(point[1:33]),
+		Y: new(big.Int).SetBytes(point[33:]),
+	}
+	if !sm2P256.isOnCurve(pub.X, pub.Y) {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKey, errSM2NotOnCurve)
+	}
+	return pub, nil
+}
+
+// ParseSM2PrivateKeyPEM 解析PKCS#8或SEC1 PEM格式的SM2私钥
+func ParseSM2PrivateKeyPEM(key string) (*SM2PrivateKey, error) {
+	block, _ := pem.Decode([]byte(key))
+	if block == nil {
+		return nil, fmt.Errorf("%w: 私钥不是PEM格式", ErrInvalidKey)
+	}
+
+	der := block.Bytes
+	var pkcs8 pkcs8PrivateKey
+	if _, err := asn1.Unmarshal(der, &pkcs8); err == nil && pkcs8.Algo.Algorithm.Equal(oidECPublicKey) {
+		if !pkcs8.Algo.Parameters.Equal(oidSM2) {
+			return nil, fmt.Errorf("%w: 不是SM2私钥", ErrInvalidKey)
+		}
+		der = pkcs8.PrivateKey
+	}
+
+	var ec ecPrivateKey
+	if _, err := asn1.Unmarshal(der, &ec); err != nil {
+		return nil, fmt.Errorf("%w: 无法解析SM2私钥", ErrInvalidKey)
+	}
+	if len(ec.NamedCurveOID) > 0 && !ec.NamedCurveOID.Equal(oidSM2) {
+		return nil, fmt.Errorf("%w: 不是SM2私钥", ErrInvalidKey)
+	}
+
+	d := new(big.Int).SetBytes(ec.PrivateKey)
+	if d.Sign() <= 0 || d.Cmp(new(big.Int).Sub(sm2P256.N, bigOne)) >= 0 {
+		return nil, fmt.Errorf("%w: SM2私钥超出范围", ErrInvalidKey)
+	}
+	var b [32]byte
+	d.FillBytes(b[:])
+	return newSM2PrivateKey(&b), nil
+}
+
+// sm2Signer SM2签名，使用默认用户身份标识，签名为Base64编码的ASN.1 DER
+type sm2Signer struct{}
+
+func (sm2Signer) Type() SignType {
+	return SignTypeSM2
+}
+
+func (sm2Signer) Asymmetric() bool {
+	return true
+}
+
+func (sm2Signer) Sign(content, key string) (string, error) {
+	priv, err := ParseSM2PrivateKeyPEM(key)
+	if err != nil {
+		return "", err
+	}
+	sig, err := SM2Sign(priv, nil, []byte(content))
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(sig), nil
+}
+
+func (sm2Signer) Verify(content, key, sign string) error {
+	pub, err := ParseSM2PublicKeyPEM(key)
+	if err != nil {
+		return err
+	}
+	sig, err := base64.StdEncoding.DecodeString(sign)
+	if err != nil || !SM2Verify(pub, nil, []byte(content), sig) {
+		return ErrInvalidSign
+	}
+	return nil
+}
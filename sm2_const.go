@@ -0,0 +1,329 @@
+package go_signature_sdk
+
+import (
+	"encoding/binary"
+	"io"
+	"math/big"
+	"math/bits"
+)
+
+// SM2 私钥和签名随机数参与的运算（生成密钥、计算公钥、签名）使用本文件的常量时间实现：
+// 定长256位的 Montgomery 模运算，射影坐标下的完全加法公式（Renes-Costello-Batina 2015，算法1），
+// 以及固定4位窗口的标量乘法。运算中没有依赖秘密数据的分支，查表时遍历整张表。
+
+// sm2Limbs 256位整数，小端序的64位字
+type sm2Limbs [4]uint64
+
+func limbsFromBytes(b *[32]byte) sm2Limbs {
+	var x sm2Limbs
+	for i := range x {
+		x[i] = binary.BigEndian.Uint64(b[32-8*(i+1):])
+	}
+	return x
+}
+
+// limbsFromBig 转换公开的常量，v 不超过256位
+func limbsFromBig(v *big.Int) sm2Limbs {
+	var b [32]byte
+	v.FillBytes(b[:])
+	return limbsFromBytes(&b)
+}
+
+func (x *sm2Limbs) bytes() [32]byte {
+	var b [32]byte
+	for i := range x {
+		binary.BigEndian.PutUint64(b[32-8*(i+1):], x[i])
+	}
+	return b
+}
+
+// isZero x 为0时返回1，否则返回0
+func (x *sm2Limbs) isZero() uint64 {
+	v := x[0] | x[1] | x[2] | x[3]
+	return 1 ^ ((v | -v) >> 63)
+}
+
+// selectLimbs cond 为1时返回 x，为0时返回 y
+func selectLimbs(cond uint64, x, y *sm2Limbs) sm2Limbs {
+	mask := -cond
+	var z sm2Limbs
+	for i := range z {
+		z[i] = (x[i] & mask) | (y[i] &^ mask)
+	}
+	return z
+}
+
+// subLimbs 返回 x - y 和借位
+func subLimbs(x, y *sm2Limbs) (sm2Limbs, uint64) {
+	var z sm2Limbs
+	var b uint64
+	for i := range z {
+		z[i], b = bits.Sub64(x[i], y[i], b)
+	}
+	return z, b
+}
+
+// sm2Modulus 模 m 的运算，m 为最高位为1的256位奇数，Montgomery 形式的 R = 2^256
+type sm2Modulus struct {
+	m     sm2Limbs
+	m0inv uint64   // -m^-1 mod 2^64
+	rr    sm2Limbs // R^2 mod m
+	one   sm2Limbs // R mod m，即 Montgomery 形式的1
+	exp   sm2Limbs // m-2，按费马小定理求逆的指数
+}
+
+func newSM2Modulus(m *big.Int) *sm2Modulus {
+	md := &sm2Modulus{m: limbsFromBig(m)}
+	inv := uint64(1)
+	for i := 0; i < 6; i++ {
+		inv *= 2 - md.m[0]*inv
+	}
+	md.m0inv = -inv
+	r := new(big.Int).Lsh(bigOne, 256)
+	md.one = limbsFromBig(new(big.Int).Mod(r, m))
+	md.rr = limbsFromBig(new(big.Int).Mod(new(big.Int).Mul(r, r), m))
+	md.exp = limbsFromBig(new(big.Int).Sub(m, big.NewInt(2)))
+	return md
+}
+
+// reduce 返回 x mod m，由于 m > 2^255，一次条件减法即可
+func (md *sm2Modulus) reduce(x *sm2Limbs) sm2Limbs {
+	d, b := subLimbs(x, &md.m)
+	return selectLimbs(b, x, &d)
+}
+
+// add 返回 (x + y) mod m，x、y < m
+func (md *sm2Modulus) add(x, y *sm2Limbs) sm2Limbs {
+	var s sm2Limbs
+	var c uint64
+	for i := range s {
+		s[i], c = bits.Add64(x[i], y[i], c)
+	}
+	d, b := subLimbs(&s, &md.m)
+	_, b = bits.Sub64(c, 0, b)
+	return selectLimbs(b, &s, &d)
+}
+
+// sub 返回 (x - y) mod m，x、y < m
+func (md *sm2Modulus) sub(x, y *sm2Limbs) sm2Limbs {
+	d, b := subLimbs(x, y)
+	mask := -b
+	var z sm2Limbs
+	var c uint64
+	for i := range z {
+		z[i], c = bits.Add64(d[i], md.m[i]&mask, c)
+	}
+	return z
+}
+
+// mul Montgomery 乘法，返回 x*y/R mod m
+func (md *sm2Modulus) mul(x, y *sm2Limbs) sm2Limbs {
+	var t [6]uint64
+	for i := 0; i < 4; i++ {
+		// t += x * y[i]
+		var c uint64
+		for j := 0; j < 4; j++ {
+			hi, lo := bits.Mul64(x[j], y[i])
+			var c1, c2 uint64
+			lo, c1 = bits.Add64(lo, t[j], 0)
+			lo, c2 = bits.Add64(lo, c, 0)
+			t[j], c = lo, hi+c1+c2
+		}
+		var c1 uint64
+		t[4], c1 = bits.Add64(t[4], c, 0)
+		t[5] = c1
+
+		// t = (t + q*m) / 2^64，q 使最低字为0
+		q := t[0] * md.m0inv
+		hi, lo := bits.Mul64(q, md.m[0])
+		_, c1 = bits.Add64(lo, t[0], 0)
+		c = hi + c1
+		for j := 1; j < 4; j++ {
+			hi, lo = bits.Mul64(q, md.m[j])
+			var c2 uint64
+			lo, c1 = bits.Add64(lo, t[j], 0)
+			lo, c2 = bits.Add64(lo, c, 0)
+			t[j-1], c = lo, hi+c1+c2
+		}
+		t[3], c1 = bits.Add64(t[4], c, 0)
+		t[4] = t[5] + c1
+	}
+
+	z := sm2Limbs{t[0], t[1], t[2], t[3]}
+	d, b := subLimbs(&z, &md.m)
+	_, b = bits.Sub64(t[4], 0, b)
+	return selectLimbs(b, &z, &d)
+}
+
+func (md *sm2Modulus) toMont(x *sm2Limbs) sm2Limbs {
+	return md.mul(x, &md.rr)
+}
+
+func (md *sm2Modulus) fromMont(x *sm2Limbs) sm2Limbs {
+	return md.mul(x, &sm2Limbs{1})
+}
+
+// inv 返回 Montgomery 形式的 x^-1，x 为0时返回0；指数 m-2 是公开的，运行时间与 x 无关
+func (md *sm2Modulus) inv(x *sm2Limbs) sm2Limbs {
+	z := md.one
+	for i := 255; i >= 0; i-- {
+		z = md.mul(&z, &z)
+		if md.exp[i/64]>>(i%64)&1 == 1 {
+			z = md.mul(&z, x)
+		}
+	}
+	return z
+}
+
+// sm2Point 射影坐标点 (X:Y:Z)，对应仿射点 (X/Z, Y/Z)，坐标为 Montgomery 形式，无穷远点为 (0:1:0)
+type sm2Point struct {
+	x, y, z sm2Limbs
+}
+
+func selectPoint(cond uint64, p, q *sm2Point) sm2Point {
+	return sm2Point{
+		x: selectLimbs(cond, &p.x, &q.x),
+		y: selectLimbs(cond, &p.y, &q.y),
+		z: selectLimbs(cond, &p.z, &q.z),
+	}
+}
+
+// sm2ConstCurve 常量时间运算使用的曲线参数
+type sm2ConstCurve struct {
+	p, n  *sm2Modulus
+	a, b3 sm2Limbs // a 和 3b，Montgomery 形式
+	// table[i] = i*G，用于固定窗口标量乘法
+	table [16]sm2Point
+}
+
+func newSM2ConstCurve(c *sm2Curve) *sm2ConstCurve {
+	cc := &sm2ConstCurve{p: newSM2Modulus(c.P), n: newSM2Modulus(c.N)}
+	a := limbsFromBig(c.A)
+	cc.a = cc.p.toMont(&a)
+	b3 := limbsFromBig(new(big.Int).Mod(new(big.Int).Mul(c.B, big.NewInt(3)), c.P))
+	cc.b3 = cc.p.toMont(&b3)
+
+	gx, gy := limbsFromBig(c.Gx), limbsFromBig(c.Gy)
+	g := sm2Point{x: cc.p.toMont(&gx), y: cc.p.toMont(&gy), z: cc.p.one}
+	cc.table[0] = sm2Point{y: cc.p.one}
+	for i := 1; i < len(cc.table); i++ {
+		cc.table[i] = cc.add(&cc.table[i-1], &g)
+	}
+	return cc
+}
+
+// sm2P256Const SM2推荐曲线的常量时间运算
+var sm2P256Const = newSM2ConstCurve(sm2P256)
+
+// add 完全加法公式，对任意两点（包括相同的点和无穷远点）都成立
+func (cc *sm2ConstCurve) add(p1, p2 *sm2Point) sm2Point {
+	f := cc.p
+	t0 := f.mul(&p1.x, &p2.x)
+	t1 := f.mul(&p1.y, &p2.y)
+	t2 := f.mul(&p1.z, &p2.z)
+	t3 := f.add(&p1.x, &p1.y)
+	t4 := f.add(&p2.x, &p2.y)
+	t3 = f.mul(&t3, &t4)
+	t4 = f.add(&t0, &t1)
+	t3 = f.sub(&t3, &t4)
+	t4 = f.add(&p1.x, &p1.z)
+	t5 := f.add(&p2.x, &p2.z)
+	t4 = f.mul(&t4, &t5)
+	t5 = f.add(&t0, &t2)
+	t4 = f.sub(&t4, &t5)
+	t5 = f.add(&p1.y, &p1.z)
+	x3 := f.add(&p2.y, &p2.z)
+	t5 = f.mul(&t5, &x3)
+	x3 = f.add(&t1, &t2)
+	t5 = f.sub(&t5, &x3)
+	z3 := f.mul(&cc.a, &t4)
+	x3 = f.mul(&cc.b3, &t2)
+	z3 = f.add(&x3, &z3)
+	x3 = f.sub(&t1, &z3)
+	z3 = f.add(&t1, &z3)
+	y3 := f.mul(&x3, &z3)
+	t1 = f.add(&t0, &t0)
+	t1 = f.add(&t1, &t0)
+	t2 = f.mul(&cc.a, &t2)
+	t4 = f.mul(&cc.b3, &t4)
+	t1 = f.add(&t1, &t2)
+	t2 = f.sub(&t0, &t2)
+	t2 = f.mul(&cc.a, &t2)
+	t4 = f.add(&t4, &t2)
+	t0 = f.mul(&t1, &t4)
+	y3 = f.add(&y3, &t0)
+	t0 = f.mul(&t5, &t4)
+	x3 = f.mul(&x3, &t3)
+	x3 = f.sub(&x3, &t0)
+	t0 = f.mul(&t3, &t1)
+	z3 = f.mul(&t5, &z3)
+	z3 = f.add(&z3, &t0)
+	return sm2Point{x: x3, y: y3, z: z3}
+}
+
+// scalarBaseMult 计算 k*G，k 为大端序，返回仿射坐标；k 为0时返回 (0, 0)
+func (cc *sm2ConstCurve) scalarBaseMult(k *[32]byte) (x, y [32]byte) {
+	q := cc.table[0]
+	for i := 0; i < 64; i++ {
+		for j := 0; j < 4; j++ {
+			q = cc.add(&q, &q)
+		}
+		w := uint64(k[i/2]>>(4*(1-i%2))) & 0xF
+		t := cc.table[0]
+		for j := 1; j < len(cc.table); j++ {
+			d := w ^ uint64(j)
+			eq := 1 ^ ((d | -d) >> 63)
+			t = selectPoint(eq, &cc.table[j], &t)
+		}
+		q = cc.add(&q, &t)
+	}
+
+	f := cc.p
+	zInv := f.inv(&q.z)
+	xm, ym := f.mul(&q.x, &zInv), f.mul(&q.y, &zInv)
+	xa, ya := f.fromMont(&xm), f.fromMont(&ym)
+	return xa.bytes(), ya.bytes()
+}
+
+// signDigest 使用私钥 d 和随机数 k 对杂凑值 e 签名，均为大端序，要求 d ∈ [1, n-2]、k ∈ [1, n-1]
+// r 或 s 不合法（概率可忽略）时 ok 为false，调用方需更换 k 重试
+func (cc *sm2ConstCurve) signDigest(d, k, e *[32]byte) (r, s [32]byte, ok bool) {
+	n := cc.n
+	x1, _ := cc.scalarBaseMult(k)
+
+	// r = (e + x1) mod n
+	eL, xL, kL, dL := limbsFromBytes(e), limbsFromBytes(&x1), limbsFromBytes(k), limbsFromBytes(d)
+	eL, xL = n.reduce(&eL), n.reduce(&xL)
+	rL := n.add(&eL, &xL)
+	rk := n.add(&rL, &kL)
+	if rL.isZero()|rk.isZero() == 1 {
+		return r, s, false
+	}
+
+	// s = (1 + d)^-1 * (k - r*d) mod n
+	rM, kM, dM := n.toMont(&rL), n.toMont(&kL), n.toMont(&dL)
+	t := n.mul(&rM, &dM)
+	t = n.sub(&kM, &t)
+	u := n.add(&n.one, &dM)
+	u = n.inv(&u)
+	sM := n.mul(&u, &t)
+	sL := n.fromMont(&sM)
+	if sL.isZero() == 1 {
+		return r, s, false
+	}
+	return rL.bytes(), sL.bytes(), true
+}
+
+// randomScalar 生成 [1, max) 内均匀分布的随机数，大端序
+func (cc *sm2ConstCurve) randomScalar(random io.Reader, max *sm2Limbs) ([32]byte, error) {
+	for {
+		var b [32]byte
+		if _, err := io.ReadFull(random, b[:]); err != nil {
+			return b, err
+		}
+		x := limbsFromBytes(&b)
+		if _, borrow := subLimbs(&x, max); borrow == 1 && x.isZero() == 0 {
+			return b, nil
+		}
+	}
+}
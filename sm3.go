@@ -0,0 +1,197 @@
+package go_signature_sdk
+
+import (
+	"crypto/subtle"
+	"encoding/binary"
+	"hash"
+	"math/bits"
+)
+
+// SM3 杂凑算法（GB/T 32905-2016）
+
+// 国密签名算法
+const (
+	SignTypeSM3     SignType = "SM3"      // 与MD5相同的拼接方式，摘要算法为SM3
+	SignTypeHMACSM3 SignType = "HMAC-SM3" // HMAC-SM3，结果为大写十六进制
+	SignTypeSM2     SignType = "SM2"      // SM2签名，用户身份标识为 SM2DefaultUID
+)
+
+func init() {
+	RegisterSigner(sm3Signer{})
+	RegisterSigner(&hmacSigner{signType: SignTypeHMACSM3, newHash: NewSM3})
+	RegisterSigner(sm2Signer{})
+}
+
+// sm3Signer SM3签名，密钥以 &key= 的形式拼接在规范化字符串末尾
+type sm3Signer struct{}
+
+func (sm3Signer) Type() SignType {
+	return SignTypeSM3
+}
+
+func (sm3Signer) Sign(content, key string) (string, error) {
+	return sm3Hash(content + "&key=" + key), nil
+}
+
+func (s sm3Signer) Verify(content, key, sign string) error {
+	expected, _ := s.Sign(content, key)
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(sign)) != 1 {
+		return ErrInvalidSign
+	}
+	return nil
+}
+
+// SM3Size SM3摘要长度
+const SM3Size = 32
+
+// SM3BlockSize SM3分组长度
+const SM3BlockSize = 64
+
+var sm3IV = [8]uint32{
+	0x7380166f, 0x4914b2b9, 0x172442d7, 0xda8a0600,
+	0xa96f30bc, 0x163138aa, 0xe38dee4d, 0xb0fb0e4e,
+}
+
+type sm3Digest struct {
+	h   [8]uint32
+	x   [SM3BlockSize]byte
+	nx  int
+	len uint64
+}
+
+// NewSM3 创建SM3哈希
+func NewSM3() hash.Hash {
+	d := new(sm3Digest)
+	d.Reset()
+	return d
+}
+
+// SM3Sum 计算SM3摘要
+func SM3Sum(data []byte) [SM3Size]byte {
+	d := new(sm3Digest)
+	d.Reset()
+	d.Write(data)
+	var sum [SM3Size]byte
+	d.checkSum(sum[:0])
+	return sum
+}
+
+func (d *sm3Digest) Reset() {
+	d.h = sm3IV
+	d.nx = 0
+	d.len = 0
+}
+
+func (d *sm3Digest) Size() int {
+	return SM3Size
+}
+
+func (d *sm3Digest) BlockSize() int {
+	return SM3BlockSize
+}
+
+func (d *sm3Digest) Write(p []byte) (int, error) {
+	n := len(p)
+	d.len += uint64(n)
+	if d.nx > 0 {
+		c := copy(d.x[d.nx:], p)
+		d.nx += c
+		if d.nx == SM3BlockSize {
+			sm3Block(&d.h, d.x[:])
+			d.nx = 0
+		}
+		p = p[c:]
+	}
+	for len(p) >= SM3BlockSize {
+		sm3Block(&d.h, p[:SM3BlockSize])
+		p = p[SM3BlockSize:]
+	}
+	if len(p) > 0 {
+		d.nx = copy(d.x[:], p)
+	}
+	return n, nil
+}
+
+func (d *sm3Digest) Sum(in []byte) []byte {
+	// 在副本上填充，不影响后续写入
+	d0 := *d
+	return d0.checkSum(in)
+}
+
+func (d *sm3Digest) checkSum(in []byte) []byte {
+	bitLen := d.len << 3
+
+	var tmp [SM3BlockSize + 8]byte
+	tmp[0] = 0x80
+	padLen := 56 - int(d.len%SM3BlockSize)
+	if padLen <= 0 {
+		padLen += SM3BlockSize
+	}
+	binary.BigEndian.PutUint64(tmp[padLen:], bitLen)
+	d.Write(tmp[:padLen+8])
+
+	var out [SM3Size]byte
+	for i, v := range d.h {
+		binary.BigEndian.PutUint32(out[i*4:], v)
+	}
+	return append(in, out[:]...)
+}
+
+func sm3P0(x uint32) uint32 {
+	return x ^ bits.RotateLeft32(x, 9) ^ bits.RotateLeft32(x, 17)
+}
+
+func sm3P1(x uint32) uint32 {
+	return x ^ bits.RotateLeft32(x, 15) ^ bits.RotateLeft32(x, 23)
+}
+
+// sm3Block 压缩一个分组
+func sm3Block(h *[8]uint32, p []byte) {
+	var w [68]uint32
+	var w1 [64]uint32
+	for i := 0; i < 16; i++ {
+		w[i] = binary.BigEndian.Uint32(p[i*4:])
+	}
+	for j := 16; j < 68; j++ {
+		w[j] = sm3P1(w[j-16]^w[j-9]^bits.RotateLeft32(w[j-3], 15)) ^ bits.RotateLeft32(w[j-13], 7) ^ w[j-6]
+	}
+	for j := 0; j < 64; j++ {
+		w1[j] = w[j] ^ w[j+4]
+	}
+
+	a, b, c, d, e, f, g, hh := h[0], h[1], h[2], h[3], h[4], h[5], h[6], h[7]
+	for j := 0; j < 64; j++ {
+		var t, ff, gg uint32
+		if j < 16 {
+			t = 0x79cc4519
+			ff = a ^ b ^ c
+			gg = e ^ f ^ g
+		} else {
+			t = 0x7a879d8a
+			ff = (a & b) | (a & c) | (b & c)
+			gg = (e & f) | (^e & g)
+		}
+		a12 := bits.RotateLeft32(a, 12)
+		ss1 := bits.RotateLeft32(a12+e+bits.RotateLeft32(t, j%32), 7)
+		ss2 := ss1 ^ a12
+		tt1 := ff + d + ss2 + w1[j]
+		tt2 := gg + hh + ss1 + w[j]
+		d = c
+		c = bits.RotateLeft32(b, 9)
+		b = a
+		a = tt1
+		hh = g
+		g = bits.RotateLeft32(f, 19)
+		f = e
+		e = sm3P0(tt2)
+	}
+
+	h[0] ^= a
+	h[1] ^= b
+	h[2] ^= c
+	h[3] ^= d
+	h[4] ^= e
+	h[5] ^= f
+	h[6] ^= g
+	h[7] ^= hh
+}
@@ -0,0 +1,183 @@
+package go_signature_sdk
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+// TestSM3 测试SM3标准示例（GB/T 32905-2016 附录A）
+func TestSM3(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "示例1",
+			input:    "abc",
+			expected: "66c7f0f462eeedd9d1f2d46bdc10e4e24167c4875cf2f7a2297da02b8f4ba8e0",
+		},
+		{
+			name:     "示例2",
+			input:    strings.Repeat("abcd", 16),
+			expected: "debe9ff92275b8a138604889c18e5a4d6fdb70e5387e5765293dcba39c0c5732",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sum := SM3Sum([]byte(tc.input))
+			if got := hex.EncodeToString(sum[:]); got != tc.expected {
+				t.Errorf("期望: %s, 实际: %s", tc.expected, got)
+			}
+
+			// 分段写入结果一致
+			h := NewSM3()
+			for _, c := range []byte(tc.input) {
+				h.Write([]byte{c})
+			}
+			if got := hex.EncodeToString(h.Sum(nil)); got != tc.expected {
+				t.Errorf("分段写入期望: %s, 实际: %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+// TestSM2Vector 测试SM2标准示例（GB/T 32918.2 附录A，Fp-256 测试曲线）
+func TestSM2Vector(t *testing.T) {
+	curve := &sm2Curve{
+		P:  mustHex("8542D69E4C044F18E8B92435BF6FF7DE457283915C45517D722EDB8B08F1DFC3"),
+		A:  mustHex("787968B4FA32C3FD2417842E73BBFEFF2F3C848B6831D7E0EC65228B3937E498"),
+		B:  mustHex("63E4C6D3B23B0C849CF84241484BFE48F61D59A5B16BA06E6E12D1DA27C5249A"),
+		N:  mustHex("8542D69E4C044F18E8B92435BF6FF7DD297720630485628D5AE74EE7C32E79B7"),
+		Gx: mustHex("421DEBD61B62EAB6746434EBC3CC315E32220B3BADD50BDC4C4E6C147FEDD43D"),
+		Gy: mustHex("0680512BCBB42C07D47349D2153B70C4E5D7FDFCBFA36EA1A85841B9E46E09A2"),
+	}
+	uid := []byte("ALICE123@YAHOO.COM")
+	msg := []byte("message digest")
+
+	// 签名使用与推荐曲线相同的常量时间实现
+	constCurve := newSM2ConstCurve(curve)
+	var d [32]byte
+	mustHex("128B2FA8BD433C6C068C8D803DFF79792A519A55171B1B650C23661D15897263").FillBytes(d[:])
+	x, y := constCurve.scalarBaseMult(&d)
+	priv := &SM2PrivateKey{SM2PublicKey: SM2PublicKey{X: new(big.Int).SetBytes(x[:]), Y: new(big.Int).SetBytes(y[:])}}
+	if priv.X.Cmp(mustHex("0AE4C7798AA0F119471BEE11825BE46202BB79E2A5844495E97C04FF4DF2548A")) != 0 ||
+		priv.Y.Cmp(mustHex("7C0240F88F1CD4E16352A73C17B7F16F07353E53A176D684A9FE0C6BB798E857")) != 0 {
+		t.Fatalf("公钥计算错误: %X, %X", priv.X, priv.Y)
+	}
+
+	za := curve.sm2Z(&priv.SM2PublicKey, uid)
+	if got := strings.ToUpper(hex.EncodeToString(za)); got != "F4A38489E32B45B6F876E3AC2168CA392362DC8F23459C1D1146FC3DBFB7BC9A" {
+		t.Errorf("Z值错误: %s", got)
+	}
+
+	e := curve.digest(&priv.SM2PublicKey, uid, msg)
+	if e.Cmp(mustHex("B524F552CD82B8B028476E005C377FB19A87E6FC682D48BB5D42E3D9B9EFFE76")) != 0 {
+		t.Errorf("e值错误: %X", e)
+	}
+
+	var k, digest [32]byte
+	mustHex("6CB28D99385C175C94F94E934817663FC176D925DD72B727260DBAAE1FB2F96F").FillBytes(k[:])
+	e.FillBytes(digest[:])
+	rb, sb, ok := constCurve.signDigest(&d, &k, &digest)
+	if !ok {
+		t.Fatal("签名失败")
+	}
+	r, s := new(big.Int).SetBytes(rb[:]), new(big.Int).SetBytes(sb[:])
+	if r.Cmp(mustHex("40F1EC59F793D9F49E09DCEF49130D4194F79FB1EED2CAA55BACDB49C4E755D1")) != 0 ||
+		s.Cmp(mustHex("6FC6DAC32C5D5CF10C77DFB20F7C2EB667A457872FB09EC56327A67EC7DEEBE7")) != 0 {
+		t.Errorf("签名错误: r=%X, s=%X", r, s)
+	}
+
+	if !curve.verify(&priv.SM2PublicKey, uid, msg, r, s) {
+		t.Error("验签失败")
+	}
+	if curve.verify(&priv.SM2PublicKey, uid, []byte("message digesT"), r, s) {
+		t.Error("篡改消息后验签应失败")
+	}
+}
+
+// TestSM2ScalarBaseMult 测试常量时间的标量乘法与 math/big 实现一致，包括窗口边界和 n-1
+func TestSM2ScalarBaseMult(t *testing.T) {
+	scalars := []*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(15), big.NewInt(16), big.NewInt(255),
+		new(big.Int).Sub(sm2P256.N, big.NewInt(2)), new(big.Int).Sub(sm2P256.N, bigOne)}
+	for i := 0; i < 8; i++ {
+		priv, err := GenerateSM2Key(rand.Reader)
+		if err != nil {
+			t.Fatalf("生成SM2密钥失败: %v", err)
+		}
+		scalars = append(scalars, priv.D)
+	}
+	for _, k := range scalars {
+		var b [32]byte
+		k.FillBytes(b[:])
+		x, y := sm2P256Const.scalarBaseMult(&b)
+		expectedX, expectedY := sm2P256.scalarBaseMult(k)
+		if new(big.Int).SetBytes(x[:]).Cmp(expectedX) != 0 || new(big.Int).SetBytes(y[:]).Cmp(expectedY) != 0 {
+			t.Errorf("%X*G 计算错误", k)
+		}
+	}
+}
+
+// TestSMSigners 测试国密签名算法
+func TestSMSigners(t *testing.T) {
+	data := map[string]interface{}{
+		"user_id": "12345",
+		"action":  "login",
+	}
+
+	priv, err := GenerateSM2Key(rand.Reader)
+	if err != nil {
+		t.Fatalf("生成SM2密钥失败: %v", err)
+	}
+	privatePEM, err := MarshalSM2PrivateKeyPEM(priv)
+	if err != nil {
+		t.Fatalf("编码SM2私钥失败: %v", err)
+	}
+	publicPEM, err := MarshalSM2PublicKeyPEM(&priv.SM2PublicKey)
+	if err != nil {
+		t.Fatalf("编码SM2公钥失败: %v", err)
+	}
+
+	testCases := []struct {
+		signType  SignType
+		signKey   string
+		verifyKey string
+	}{
+		{SignTypeSM3, "test_secret", "test_secret"},
+		{SignTypeHMACSM3, "test_secret", "test_secret"},
+		{SignTypeSM2, privatePEM, publicPEM},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.signType), func(t *testing.T) {
+			sign, _, err := GenerateSignWith(tc.signType, data, tc.signKey)
+			if err != nil {
+				t.Fatalf("签名生成失败: %v", err)
+			}
+
+			params := &VerifyParams{Data: copyData(data)}
+			params.Data["sign"] = sign
+			if err := VerifySignWith(params, tc.signType, tc.verifyKey); err != nil {
+				t.Errorf("签名验证失败: %v", err)
+			}
+
+			params.Data["sign"] = sign
+			params.Data["action"] = "logout"
+			if err := VerifySignWith(params, tc.signType, tc.verifyKey); err != ErrInvalidSign {
+				t.Errorf("期望签名验证失败, 实际: %v", err)
+			}
+		})
+	}
+
+	if !IsAsymmetric(SignTypeSM2) || IsAsymmetric(SignTypeHMACSM3) {
+		t.Error("非对称算法判断错误")
+	}
+	if err := ValidatePublicKey(SignTypeSM2, publicPEM); err != nil {
+		t.Errorf("SM2公钥校验失败: %v", err)
+	}
+}
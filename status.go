@@ -0,0 +1,128 @@
+package go_signature_sdk
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// AppStatus 应用状态，数值与 app_keys.status 一致
+type AppStatus int
+
+const (
+	StatusSuspended AppStatus = 0 // 暂停，可恢复为 active
+	StatusActive    AppStatus = 1 // 正常
+	StatusPending   AppStatus = 2 // 待审核，审核通过后变为 active
+	StatusRevoked   AppStatus = 3 // 已吊销，不可恢复
+	StatusDeleted   AppStatus = 4 // 已删除
+)
+
+// statusTransitions 允许的状态变更
+var statusTransitions = map[AppStatus][]AppStatus{
+	StatusPending:   {StatusActive, StatusRevoked, StatusDeleted},
+	StatusActive:    {StatusSuspended, StatusRevoked, StatusDeleted},
+	StatusSuspended: {StatusActive, StatusRevoked, StatusDeleted},
+	StatusRevoked:   {StatusDeleted},
+	StatusDeleted:   {},
+}
+
+// String 返回状态名称
+func (st AppStatus) String() string {
+	switch st {
+	case StatusSuspended:
+		return "suspended"
+	case StatusActive:
+		return "active"
+	case StatusPending:
+		return "pending"
+	case StatusRevoked:
+		return "revoked"
+	case StatusDeleted:
+		return "deleted"
+	default:
+		return "status(" + strconv.Itoa(int(st)) + ")"
+	}
+}
+
+// CanTransition 返回是否允许从 from 变更为 to，状态不变时返回true
+// 旧版本写入的未知状态可以变更为任意已知状态
+func CanTransition(from, to AppStatus) bool {
+	if _, ok := statusTransitions[to]; !ok {
+		return false
+	}
+	if from == to {
+		return true
+	}
+	next, ok := statusTransitions[from]
+	if !ok {
+		return true
+	}
+	for _, st := range next {
+		if st == to {
+			return true
+		}
+	}
+	return false
+}
+
+// checkTransition 不允许的状态变更返回 ErrInvalidTransition
+func checkTransition(from, to AppStatus) error {
+	if !CanTransition(from, to) {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, from, to)
+	}
+	return nil
+}
+
+// statusError 返回非 active 状态对应的错误，均可用 errors.Is 判断为 ErrAppDisabled
+func statusError(status AppStatus) error {
+	switch status {
+	case StatusActive:
+		return nil
+	case StatusSuspended:
+		return ErrAppSuspended
+	case StatusPending:
+		return ErrAppPending
+	case StatusRevoked:
+		return ErrAppRevoked
+	case StatusDeleted:
+		return ErrAppDeleted
+	default:
+		return ErrAppDisabled
+	}
+}
+
+// applyStatusChange 校验并修改应用状态，状态不变时返回false
+func applyStatusChange(appKey *AppKey, change *StatusChange, now int64) (bool, error) {
+	if err := checkTransition(appKey.Status, change.Status); err != nil {
+		return false, err
+	}
+	if appKey.Status == change.Status {
+		return false, nil
+	}
+	appKey.Status = change.Status
+	appKey.StatusReason = change.Reason
+	appKey.StatusActor = change.Actor
+	appKey.StatusChangedAt = now
+	if change.Status == StatusDeleted {
+		appKey.DeletedAt = now
+	}
+	return true, nil
+}
+
+// StatusChange 状态变更
+type StatusChange struct {
+	Status AppStatus
+	Reason string // 变更原因，如 "欠费暂停"，不会返回给合作方
+	Actor  string // 操作人
+}
+
+// contextStatusChange UpdateAppKey、PatchAppKey 中的状态变更，操作人取自 ctx
+func contextStatusChange(ctx context.Context, status AppStatus, reason string) *StatusChange {
+	return &StatusChange{Status: status, Reason: reason, Actor: actorFromContext(ctx)}
+}
+
+// SetStatus 按状态变更规则修改应用状态，并记录原因和操作人
+// 不允许的变更返回 ErrInvalidTransition，状态不变时不做任何修改
+func (s *SignatureSDK) SetStatus(ctx context.Context, appID string, change StatusChange) error {
+	return s.store.SetStatus(withChangeActor(ctx, &change), appID, &change)
+}
@@ -0,0 +1,116 @@
+package go_signature_sdk
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// TestCanTransition 测试状态变更规则
+func TestCanTransition(t *testing.T) {
+	cases := []struct {
+		from, to AppStatus
+		allowed  bool
+	}{
+		{StatusPending, StatusActive, true},
+		{StatusActive, StatusSuspended, true},
+		{StatusSuspended, StatusActive, true},
+		{StatusActive, StatusRevoked, true},
+		{StatusRevoked, StatusDeleted, true},
+		{StatusActive, StatusActive, true},
+		{AppStatus(9), StatusActive, true},
+		{StatusRevoked, StatusActive, false},
+		{StatusDeleted, StatusActive, false},
+		{StatusActive, StatusPending, false},
+		{StatusActive, AppStatus(9), false},
+	}
+	for _, tc := range cases {
+		if got := CanTransition(tc.from, tc.to); got != tc.allowed {
+			t.Errorf("%s -> %s: 期望 %v, 实际 %v", tc.from, tc.to, tc.allowed, got)
+		}
+	}
+}
+
+// TestAppStatus 测试内存存储的应用状态
+func TestAppStatus(t *testing.T) {
+	testAppStatus(t, createMemorySDK(t))
+}
+
+// TestSDKAppStatus 测试PostgreSQL存储的应用状态
+func TestSDKAppStatus(t *testing.T) {
+	sdk, db := createTestSDK(t)
+	defer teardownTestDB(t, db)
+	testAppStatus(t, sdk)
+}
+
+func testAppStatus(t *testing.T, sdk *SignatureSDK) {
+	ctx := context.Background()
+	creds, err := sdk.IssueCredentials(ctx, IssueOptions{Pending: true})
+	if err != nil {
+		t.Fatalf("签发凭证失败: %v", err)
+	}
+	appID := creds.AppID
+	verify := func() error {
+		_, err := sdk.VerifyIPs(appID, "127.0.0.1")
+		return err
+	}
+	if err := verify(); !errors.Is(err, ErrAppPending) || !errors.Is(err, ErrAppDisabled) {
+		t.Errorf("期望待审核错误, 实际: %v", err)
+	}
+
+	if err := sdk.SetStatus(ctx, appID, StatusChange{Status: StatusActive, Reason: "审核通过", Actor: "alice"}); err != nil {
+		t.Fatalf("启用应用失败: %v", err)
+	}
+	if err := verify(); err != nil {
+		t.Errorf("启用后验证失败: %v", err)
+	}
+
+	if err := sdk.SetStatus(ctx, appID, StatusChange{Status: StatusSuspended, Reason: "欠费", Actor: "bob"}); err != nil {
+		t.Fatalf("暂停应用失败: %v", err)
+	}
+	appKey, _ := sdk.GetAppKey(appID)
+	if appKey.Status != StatusSuspended || appKey.StatusReason != "欠费" || appKey.StatusActor != "bob" || appKey.StatusChangedAt == 0 {
+		t.Errorf("状态变更记录错误: %+v", appKey)
+	}
+	if err := verify(); !errors.Is(err, ErrAppSuspended) {
+		t.Errorf("期望已暂停错误, 实际: %v", err)
+	}
+	if err, _ := sdk.GenerateSign(&SignParams{AppID: appID, Data: map[string]interface{}{}}); !errors.Is(err, ErrAppSuspended) {
+		t.Errorf("期望已暂停错误, 实际: %v", err)
+	}
+
+	// 状态不变时不修改
+	version := appKey.Version
+	if err := sdk.SetStatus(ctx, appID, StatusChange{Status: StatusSuspended, Reason: "重复"}); err != nil {
+		t.Errorf("状态不变时不应报错: %v", err)
+	}
+	if appKey, _ := sdk.GetAppKey(appID); appKey.Version != version || appKey.StatusReason != "欠费" {
+		t.Errorf("状态不变时不应修改: %+v", appKey)
+	}
+
+	if err := sdk.SetStatus(ctx, appID, StatusChange{Status: StatusRevoked, Reason: "密钥泄露"}); err != nil {
+		t.Fatalf("吊销应用失败: %v", err)
+	}
+	if err := verify(); !errors.Is(err, ErrAppRevoked) || errors.Is(err, ErrAppSuspended) {
+		t.Errorf("期望已吊销错误, 实际: %v", err)
+	}
+
+	// 吊销后不能恢复
+	if err := sdk.SetStatus(ctx, appID, StatusChange{Status: StatusActive}); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("期望状态变更错误, 实际: %v", err)
+	}
+	if err := sdk.UpdateAppKey(appID, creds.Secret, nil, int(StatusActive), nil); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("期望状态变更错误, 实际: %v", err)
+	}
+	active := StatusActive
+	if _, err := sdk.PatchAppKey(ctx, appID, AppKeyPatch{Status: &active}); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("期望状态变更错误, 实际: %v", err)
+	}
+	if appKey, _ := sdk.GetAppKey(appID); appKey.Status != StatusRevoked {
+		t.Errorf("不允许的变更不应写入: %s", appKey.Status)
+	}
+
+	if err := sdk.SetStatus(ctx, "not_exist", StatusChange{Status: StatusActive}); !errors.Is(err, ErrAppNotFound) {
+		t.Errorf("期望应用不存在错误, 实际: %v", err)
+	}
+}
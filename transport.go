@@ -0,0 +1,219 @@
+package go_signature_sdk
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// SignLocation 签名放置的位置
+type SignLocation int
+
+const (
+	// SignInHeader 签名放在 X-Sign 请求头中
+	SignInHeader SignLocation = iota
+	// SignInBody 签名作为参数放在JSON/表单请求体中，没有请求体时放在查询参数中
+	SignInBody
+)
+
+// SigningTransport 为出站请求签名的 http.RoundTripper
+// 每次 RoundTrip 都会生成新的时间戳和nonce，重试的请求会被重新签名
+type SigningTransport struct {
+	// AppID 应用ID，通过 X-App-ID 请求头发送
+	AppID string
+	// SecretKey 签名密钥，非对称算法为PEM私钥；设置 SDK 时不需要
+	SecretKey string
+	// SignType 签名算法，为空时使用 SDK 中应用配置的算法，未设置 SDK 时为MD5
+	SignType SignType
+	// SDK 设置后从应用记录中获取密钥和算法
+	SDK *SignatureSDK
+	// PrivateKey 通过 SDK 签名且应用使用非对称算法时的PEM私钥
+	PrivateKey string
+	// Location 签名放置的位置，默认 SignInHeader
+	Location SignLocation
+	// SignField 签名放在请求体中时的字段名，默认 sign
+	SignField string
+	// Base 实际发送请求的 RoundTripper，默认 http.DefaultTransport
+	Base http.RoundTripper
+	// Clock 时钟，为空时使用 time.Now
+	Clock func() time.Time
+	// NonceFunc nonce生成函数，默认生成32位随机十六进制字符串
+	NonceFunc func() string
+}
+
+// RoundTrip 签名并发送请求
+func (t *SigningTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	signed, err := t.signRequest(req)
+	if err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(signed)
+}
+
+// signRequest 复制请求并添加签名，不修改原请求
+func (t *SigningTransport) signRequest(req *http.Request) (*http.Request, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	signField := t.SignField
+	if signField == "" {
+		signField = "sign"
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	data := make(map[string]interface{})
+	mergeValues(data, req.URL.Query())
+	if err := mergeBodyParams(data, mediaType, body); err != nil {
+		return nil, err
+	}
+	// 重试的请求可能已带有上次的签名，自定义 SignField 时同名的业务参数 sign 参与签名
+	delete(data, signField)
+
+	timestamp := t.now().Unix()
+	nonce := t.nonce()
+	sign, err := t.sign(req.Context(), data, timestamp, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	signed := req.Clone(req.Context())
+	signed.Header.Set(HeaderAppID, t.AppID)
+	signed.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	signed.Header.Set(HeaderNonce, nonce)
+	signed.Header.Del(HeaderSign)
+
+	if t.Location == SignInBody {
+		if body, err = t.signBody(signed, mediaType, body, signField, sign); err != nil {
+			return nil, err
+		}
+	} else {
+		signed.Header.Set(HeaderSign, sign)
+	}
+
+	setRequestBody(signed, body)
+	return signed, nil
+}
+
+// sign 计算签名
+func (t *SigningTransport) sign(ctx context.Context, data map[string]interface{}, timestamp int64, nonce string) (string, error) {
+	if t.SDK != nil {
+		params := &SignParams{
+			AppID:      t.AppID,
+			Timestamp:  timestamp,
+			Nonce:      nonce,
+			Data:       data,
+			SignType:   t.SignType,
+			PrivateKey: t.PrivateKey,
+		}
+		return t.SDK.Sign(ctx, params)
+	}
+
+	applyTimestampNonce(data, timestamp, nonce)
+	sign, _, err := GenerateSignWith(t.SignType, data, t.SecretKey)
+	return sign, err
+}
+
+// signBody 将签名写入请求体，没有可写入的请求体时写入查询参数
+func (t *SigningTransport) signBody(req *http.Request, mediaType string, body []byte, field, sign string) ([]byte, error) {
+	switch {
+	case mediaType == "application/x-www-form-urlencoded":
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return nil, fmt.Errorf("%w: 解析表单失败: %v", ErrInvalidRequest, err)
+		}
+		values.Set(field, sign)
+		return []byte(values.Encode()), nil
+	case isJSONMediaType(mediaType) && len(bytes.TrimSpace(body)) > 0:
+		fields, err := decodeJSONObject(body)
+		if err != nil {
+			return nil, err
+		}
+		fields[field] = sign
+		return json.Marshal(fields)
+	default:
+		query := req.URL.Query()
+		query.Set(field, sign)
+		req.URL.RawQuery = query.Encode()
+		return body, nil
+	}
+}
+
+func (t *SigningTransport) now() time.Time {
+	if t.Clock != nil {
+		return t.Clock()
+	}
+	return time.Now()
+}
+
+func (t *SigningTransport) nonce() string {
+	if t.NonceFunc != nil {
+		return t.NonceFunc()
+	}
+	return generateNonce()
+}
+
+// generateNonce 生成32位随机十六进制字符串
+func generateNonce() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// readRequestBody 读取请求体，优先使用 GetBody 以便重试时获取原始请求体
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	var rc io.ReadCloser = req.Body
+	if req.GetBody != nil {
+		var err error
+		if rc, err = req.GetBody(); err != nil {
+			return nil, fmt.Errorf("获取请求体失败: %w", err)
+		}
+		req.Body.Close()
+	}
+	defer rc.Close()
+
+	body, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, fmt.Errorf("读取请求体失败: %w", err)
+	}
+	return body, nil
+}
+
+// setRequestBody 设置可重复读取的请求体
+func setRequestBody(req *http.Request, body []byte) {
+	if body == nil {
+		req.Body = http.NoBody
+		req.GetBody = func() (io.ReadCloser, error) { return http.NoBody, nil }
+		req.ContentLength = 0
+		return
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	req.ContentLength = int64(len(body))
+}
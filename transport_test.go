@@ -0,0 +1,143 @@
+package go_signature_sdk
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// newVerifyServer 创建校验签名的测试服务，首次请求返回307以触发客户端重发
+func newVerifyServer(t *testing.T, secretKey string, nonces map[string]bool) *httptest.Server {
+	var requests int
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		nonces[r.Header.Get(HeaderNonce)] = true
+		if requests == 1 {
+			http.Redirect(w, r, r.URL.String(), http.StatusTemporaryRedirect)
+			return
+		}
+
+		data, err := extractRequestParams(r, DefaultMaxBodySize)
+		if err != nil {
+			t.Errorf("提取参数失败: %v", err)
+		}
+		timestamp, _ := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+		params := &VerifyParams{
+			AppID:     r.Header.Get(HeaderAppID),
+			Timestamp: timestamp,
+			Nonce:     r.Header.Get(HeaderNonce),
+			Sign:      r.Header.Get(HeaderSign),
+			Data:      data,
+		}
+		applyTimestampNonce(params.Data, params.Timestamp, params.Nonce)
+		if err := VerifySignWith(params, SignTypeHMACSHA256, secretKey); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		fmt.Fprintf(w, "%s", body)
+	}))
+}
+
+// TestSigningTransport 测试出站请求签名
+func TestSigningTransport(t *testing.T) {
+	secretKey := "test_secret_transport"
+
+	testCases := []struct {
+		name        string
+		method      string
+		query       string
+		contentType string
+		body        string
+		location    SignLocation
+	}{
+		{name: "GET查询参数", method: http.MethodGet, query: "user_id=12345&action=login"},
+		{name: "GET签名放在查询参数", method: http.MethodGet, query: "user_id=12345", location: SignInBody},
+		{
+			name:        "表单",
+			method:      http.MethodPost,
+			query:       "action=update",
+			contentType: "application/x-www-form-urlencoded",
+			body:        url.Values{"user_id": {"12345"}}.Encode(),
+		},
+		{
+			name:        "JSON签名放在请求体",
+			method:      http.MethodPost,
+			contentType: "application/json",
+			body:        `{"user":{"id":123},"amount":10000000}`,
+			location:    SignInBody,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			nonces := make(map[string]bool)
+			server := newVerifyServer(t, secretKey, nonces)
+			defer server.Close()
+
+			client := &http.Client{Transport: &SigningTransport{
+				AppID:     "test_app",
+				SecretKey: secretKey,
+				SignType:  SignTypeHMACSHA256,
+				Location:  tc.location,
+			}}
+
+			req, _ := http.NewRequest(tc.method, server.URL+"/api/user?"+tc.query, strings.NewReader(tc.body))
+			if tc.contentType != "" {
+				req.Header.Set("Content-Type", tc.contentType)
+			}
+
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("请求失败: %v", err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("验签失败: %d %s", resp.StatusCode, body)
+			}
+
+			// 重发的请求使用了新的nonce
+			if len(nonces) != 2 {
+				t.Errorf("期望重发请求前后nonce不同, 实际: %v", nonces)
+			}
+			if req.Header.Get(HeaderSign) != "" {
+				t.Error("原请求被修改")
+			}
+		})
+	}
+}
+
+// TestSigningTransportSignField 测试自定义签名字段时业务参数 sign 参与签名
+func TestSigningTransportSignField(t *testing.T) {
+	secretKey := "test_secret_transport"
+	transport := &SigningTransport{
+		AppID:     "test_app",
+		SecretKey: secretKey,
+		SignType:  SignTypeHMACSHA256,
+		Location:  SignInBody,
+		SignField: "signature",
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com/api/user?sign=business&user_id=12345", nil)
+	signed, err := transport.signRequest(req)
+	if err != nil {
+		t.Fatalf("签名失败: %v", err)
+	}
+
+	data := make(map[string]interface{})
+	mergeValues(data, signed.URL.Query())
+	if data["sign"] != "business" {
+		t.Errorf("业务参数 sign 被删除: %v", data)
+	}
+	timestamp, _ := strconv.ParseInt(signed.Header.Get(HeaderTimestamp), 10, 64)
+	applyTimestampNonce(data, timestamp, signed.Header.Get(HeaderNonce))
+	if err := Verify(data, secretKey, WithAlgorithm(SignTypeHMACSHA256), WithSignField("signature")); err != nil {
+		t.Errorf("验签失败: %v", err)
+	}
+}
@@ -1,33 +1,92 @@
 package go_signature_sdk
 
-import "database/sql"
+import (
+	"database/sql"
+	"time"
+)
 
 // Config SDK配置
 type Config struct {
 	DB *sql.DB
+	// SkipMigrations 为true时创建SDK不执行数据库迁移，由部署流程调用 Migrate
+	SkipMigrations bool
+	// KeyStore 应用密钥存储，为空时使用 DB 创建 PostgresKeyStore
+	KeyStore KeyStore
+	// KeyEncryption 使用 DB 创建 PostgresKeyStore 时用于加密存储密钥，为空时明文存储
+	KeyEncryption KeyEncryptionProvider
+	// RowMACKey 使用 DB 创建 PostgresKeyStore 时用于计算和校验 row_mac，为空时不校验
+	RowMACKey []byte
+	// QueryTimeout 使用 DB 创建 PostgresKeyStore 时每次查询或事务的超时时间，
+	// 调用方 ctx 的截止时间更早时以 ctx 为准；为0时只受 ctx 控制
+	QueryTimeout time.Duration
+	// CredentialPolicy 创建应用和更新、轮换密钥时的校验规则，为空时只要求密钥非空、应用ID不超过32个字符
+	// 推荐使用 DefaultCredentialPolicy
+	CredentialPolicy *CredentialPolicy
+	// Cache 应用密钥缓存配置，为空时不启用缓存
+	Cache *CacheConfig
+	// ListenDSN 启用缓存时，通过该连接串 LISTEN app_keys 的变更通知，及时清除其他实例修改的应用
+	ListenDSN string
+
+	// TimestampSkew 时间戳允许的误差，默认 DefaultTimestampSkew
+	TimestampSkew time.Duration
+	// RequireTimestamp 为true时拒绝未携带时间戳的请求
+	RequireTimestamp bool
+	// NonceStore nonce存储，为空时不做nonce去重
+	NonceStore NonceStore
+	// RequireNonce 为true时拒绝未携带nonce的请求
+	RequireNonce bool
+	// Clock 时钟，为空时使用 time.Now
+	Clock func() time.Time
+
+	// DeleteRetention 删除的应用可以恢复的期限，默认 DefaultDeleteRetention
+	DeleteRetention time.Duration
+
+	// OnSecretEvent 密钥轮换和过期清理后同步调用，用于通知或审计
+	OnSecretEvent func(SecretEvent)
 }
 
 // AppKey 应用密钥信息
 type AppKey struct {
 	ID         int                    `json:"id"`
 	AppID      string                 `json:"app_id"`
-	SecretKey  string                 `json:"secret_key"`
+	SecretKey  string                 `json:"secret_key"` // 主密钥，与 Secrets 中 Primary 的密钥一致
 	IPsWhite   []string               `json:"ips_white"`
-	Status     int                    `json:"status"`
+	Status     AppStatus              `json:"status"`
 	CreateAt   int64                  `json:"create_at"`
 	UpdateAt   *int64                 `json:"update_at"`
 	Attributes map[string]interface{} `json:"attributes"`
+
+	SignType         SignType   `json:"sign_type"`          // 生成签名使用的算法
+	AllowedSignTypes []SignType `json:"allowed_sign_types"` // 验签接受的算法，为空时仅接受 SignType
+	PublicKey        string     `json:"public_key"`         // 非对称算法验签使用的PEM公钥
+
+	Secrets []AppSecret `json:"secrets,omitempty"` // 全部对称密钥，验签时接受其中当前有效的密钥
+	Version int64       `json:"version"`           // 版本号，每次修改加1，用于 PatchAppKey 的乐观并发控制
+
+	StatusReason    string `json:"status_reason,omitempty"`     // 最近一次状态变更的原因
+	StatusActor     string `json:"status_actor,omitempty"`      // 最近一次状态变更的操作人
+	StatusChangedAt int64  `json:"status_changed_at,omitempty"` // 最近一次状态变更的时间戳
+	DeletedAt       int64  `json:"deleted_at,omitempty"`        // 软删除的时间戳，0表示未删除
 }
 
 // SignParams 签名参数
 type SignParams struct {
-	AppID string                 `json:"app_id"`
-	Data  map[string]interface{} `json:"data"`
+	AppID     string                 `json:"app_id"`
+	Timestamp int64                  `json:"timestamp,omitempty"` // 非0时写入 Data["timestamp"]
+	Nonce     string                 `json:"nonce,omitempty"`     // 非空时写入 Data["nonce"]
+	Data      map[string]interface{} `json:"data"`
+	SignType  SignType               `json:"sign_type,omitempty"` // 为空时使用应用配置的算法
+
+	// PrivateKey 非对称算法签名使用的PEM私钥，私钥不入库，由调用方持有
+	PrivateKey string `json:"-"`
 }
 
 // VerifyParams 验签参数
 type VerifyParams struct {
-	AppID    string                 `json:"app_id"`
-	Data     map[string]interface{} `json:"data"`
-	ClientIP string                 `json:"client_ip"`
+	AppID     string                 `json:"app_id"`
+	Timestamp int64                  `json:"timestamp,omitempty"` // 为0时从 Data["timestamp"] 读取
+	Nonce     string                 `json:"nonce,omitempty"`     // 为空时从 Data["nonce"] 读取
+	Sign      string                 `json:"sign,omitempty"`      // 为空时从 Data["sign"] 读取
+	Data      map[string]interface{} `json:"data"`
+	ClientIP  string                 `json:"client_ip"`
 }
@@ -0,0 +1,172 @@
+package go_signature_sdk
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// UsageStat 使用统计
+type UsageStat struct {
+	Count      int64     `json:"count"`
+	LastUsedAt time.Time `json:"last_used_at"`
+}
+
+// UsageKind 使用统计的类型
+type UsageKind string
+
+const (
+	UsageSignType UsageKind = "sign_type" // Name 为签名算法
+	UsageSecret   UsageKind = "secret"    // Name 为密钥ID
+)
+
+// UsageRecord 导出的使用统计，Count 为上次导出以来的增量，可在多个实例间累加
+type UsageRecord struct {
+	AppID      string    `json:"app_id"`
+	Kind       UsageKind `json:"kind"`
+	Name       string    `json:"name"`
+	Count      int64     `json:"count"`
+	LastUsedAt time.Time `json:"last_used_at"`
+}
+
+// usageRecorder 按应用记录各项的使用情况
+type usageRecorder struct {
+	mu        sync.Mutex
+	data      map[string]map[string]*UsageStat
+	pending   map[string]map[string]*UsageStat // 尚未导出的增量，只在有导出任务时记录
+	exporters int                              // 运行中的导出任务数
+}
+
+func newUsageRecorder() *usageRecorder {
+	return &usageRecorder{
+		data:    make(map[string]map[string]*UsageStat),
+		pending: make(map[string]map[string]*UsageStat),
+	}
+}
+
+// record 记录一次使用
+func (r *usageRecorder) record(appID, name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	addUsage(r.data, appID, name, 1, now)
+	if r.exporters > 0 {
+		addUsage(r.pending, appID, name, 1, now)
+	}
+}
+
+// setExporting 登记或注销一个导出任务，没有导出任务后不再记录增量
+func (r *usageRecorder) setExporting(exporting bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if exporting {
+		r.exporters++
+	} else {
+		r.exporters--
+	}
+}
+
+// addUsage 累加 appID 下 name 的使用次数
+func addUsage(m map[string]map[string]*UsageStat, appID, name string, count int64, lastUsedAt time.Time) {
+	stats, ok := m[appID]
+	if !ok {
+		stats = make(map[string]*UsageStat)
+		m[appID] = stats
+	}
+	stat, ok := stats[name]
+	if !ok {
+		stat = &UsageStat{}
+		stats[name] = stat
+	}
+	stat.Count += count
+	if lastUsedAt.After(stat.LastUsedAt) {
+		stat.LastUsedAt = lastUsedAt
+	}
+}
+
+// snapshot 返回应用使用统计的副本
+func (r *usageRecorder) snapshot(appID string) map[string]UsageStat {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := make(map[string]UsageStat, len(r.data[appID]))
+	for k, v := range r.data[appID] {
+		result[k] = *v
+	}
+	return result
+}
+
+// drain 取出尚未导出的增量
+func (r *usageRecorder) drain(kind UsageKind) []UsageRecord {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var records []UsageRecord
+	for appID, stats := range r.pending {
+		for name, stat := range stats {
+			records = append(records, UsageRecord{AppID: appID, Kind: kind, Name: name, Count: stat.Count, LastUsedAt: stat.LastUsedAt})
+		}
+	}
+	r.pending = make(map[string]map[string]*UsageStat)
+	return records
+}
+
+// restore 将导出失败的增量放回，下次导出时重试；没有导出任务时丢弃
+func (r *usageRecorder) restore(records []UsageRecord) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.exporters == 0 {
+		return
+	}
+	for _, record := range records {
+		addUsage(r.pending, record.AppID, record.Name, record.Count, record.LastUsedAt)
+	}
+}
+
+// StartUsageExporter 启动后台定时导出签名算法和密钥的使用统计，返回停止函数
+// SignTypeUsage 和 SecretUsage 只统计本实例，多实例部署时由 export 汇总持久化，用于判断迁移进度。
+// 增量只在导出任务运行期间记录，启动前和停止后的使用不会导出。
+// export 返回错误时本次增量保留到下次导出；停止时会等待最后一次导出完成。每个实例只应启动一个。
+func (s *SignatureSDK) StartUsageExporter(interval time.Duration, export func(ctx context.Context, records []UsageRecord) error) (stop func()) {
+	s.signTypeUsage.setExporting(true)
+	s.secretUsage.setExporting(true)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	flush := func(ctx context.Context) {
+		signTypes := s.signTypeUsage.drain(UsageSignType)
+		secrets := s.secretUsage.drain(UsageSecret)
+		if len(signTypes)+len(secrets) == 0 {
+			return
+		}
+		if err := export(ctx, append(signTypes, secrets...)); err != nil {
+			log.Println("导出使用统计失败:", err)
+			s.signTypeUsage.restore(signTypes)
+			s.secretUsage.restore(secrets)
+		}
+	}
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				// 先注销再导出，最后一次导出失败时增量被丢弃
+				s.signTypeUsage.setExporting(false)
+				s.secretUsage.setExporting(false)
+				flush(context.Background())
+				return
+			case <-ticker.C:
+				flush(ctx)
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
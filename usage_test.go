@@ -0,0 +1,76 @@
+package go_signature_sdk
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestUsageExporter 测试使用统计按增量导出，导出失败时保留到下次
+func TestUsageExporter(t *testing.T) {
+	sdk := createMemorySDK(t)
+	ctx := context.Background()
+	if err := sdk.CreateAppKey("test_app", "test_secret", nil, nil); err != nil {
+		t.Fatalf("创建测试应用失败: %v", err)
+	}
+	verify := func() {
+		sign, err := sdk.Sign(ctx, &SignParams{AppID: "test_app", Data: map[string]interface{}{"user_id": "1"}})
+		if err != nil {
+			t.Fatalf("签名生成失败: %v", err)
+		}
+		params := &VerifyParams{AppID: "test_app", ClientIP: "127.0.0.1", Sign: sign, Data: map[string]interface{}{"user_id": "1"}}
+		if _, err := sdk.Verify(ctx, params); err != nil {
+			t.Fatalf("签名验证失败: %v", err)
+		}
+	}
+
+	// 没有导出任务时不记录增量
+	verify()
+	if len(sdk.signTypeUsage.pending) != 0 || len(sdk.secretUsage.pending) != 0 {
+		t.Errorf("未启动导出时不应记录增量")
+	}
+
+	var mu sync.Mutex
+	var exported []UsageRecord
+	fail := true
+	stop := sdk.StartUsageExporter(10*time.Millisecond, func(ctx context.Context, records []UsageRecord) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if fail {
+			fail = false
+			return errors.New("export failed")
+		}
+		exported = append(exported, records...)
+		return nil
+	})
+	verify()
+	verify()
+	time.Sleep(50 * time.Millisecond)
+	verify()
+	stop()
+	verify()
+	if len(sdk.signTypeUsage.pending) != 0 || len(sdk.secretUsage.pending) != 0 {
+		t.Errorf("停止导出后不应记录增量")
+	}
+
+	counts := make(map[UsageKind]int64)
+	for _, record := range exported {
+		if record.AppID != "test_app" || record.LastUsedAt.IsZero() {
+			t.Errorf("导出记录错误: %+v", record)
+		}
+		if record.Kind == UsageSignType && record.Name != string(SignTypeMD5) {
+			t.Errorf("导出的签名算法错误: %+v", record)
+		}
+		counts[record.Kind] += record.Count
+	}
+	if counts[UsageSignType] != 3 || counts[UsageSecret] != 3 {
+		t.Errorf("导出的使用次数错误: %v", counts)
+	}
+
+	// 导出不影响本实例的累计统计
+	if sdk.SignTypeUsage("test_app")[SignTypeMD5].Count != 5 {
+		t.Errorf("MD5使用统计错误: %v", sdk.SignTypeUsage("test_app"))
+	}
+}
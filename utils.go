@@ -15,6 +15,12 @@ func md5Hash(text string) string {
 	return strings.ToUpper(hex.EncodeToString(hash[:]))
 }
 
+// sm3Hash 生成SM3哈希
+func sm3Hash(text string) string {
+	hash := SM3Sum([]byte(text))
+	return strings.ToUpper(hex.EncodeToString(hash[:]))
+}
+
 // formatValue 格式化值，避免科学计数法
 func formatValue(v interface{}) string {
 	switch val := v.(type) {
@@ -64,6 +70,11 @@ func flattenMap(data interface{}, prefix string, result map[string]string) {
 
 // buildSignString 构建签名字符串
 func buildSignString(data map[string]interface{}, secretKey string) string {
+	return buildCanonicalString(data) + "&key=" + secretKey
+}
+
+// buildCanonicalString 构建不含密钥的规范化字符串
+func buildCanonicalString(data map[string]interface{}) string {
 	// 展开所有嵌套参数
 	allParams := make(map[string]string)
 
@@ -102,6 +113,5 @@ func buildSignString(data map[string]interface{}, secretKey string) string {
 		}
 	}
 
-	signStr := strings.Join(signParts, "&") + "&key=" + secretKey
-	return signStr
+	return strings.Join(signParts, "&")
 }
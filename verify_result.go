@@ -0,0 +1,105 @@
+package go_signature_sdk
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+// VerifyReason 验签失败的原因，用于日志、监控等程序判断
+type VerifyReason string
+
+const (
+	ReasonMissingSign        VerifyReason = "missing_sign"
+	ReasonInvalidRequest     VerifyReason = "invalid_request"
+	ReasonAppNotFound        VerifyReason = "app_not_found" // 含校验码错误的应用ID
+	ReasonIPNotAllowed       VerifyReason = "ip_not_allowed"
+	ReasonAppDisabled        VerifyReason = "app_disabled" // 应用已暂停、待审核、吊销或删除
+	ReasonSignTypeNotAllowed VerifyReason = "sign_type_not_allowed"
+	ReasonSignMismatch       VerifyReason = "sign_mismatch"
+	ReasonMissingTimestamp   VerifyReason = "missing_timestamp"
+	ReasonExpired            VerifyReason = "expired"
+	ReasonMissingNonce       VerifyReason = "missing_nonce"
+	ReasonReplayed           VerifyReason = "replayed"
+	ReasonInternal           VerifyReason = "internal" // 存储错误、ctx 取消或超时等
+)
+
+// VerifyTimings 验签各阶段的耗时，未执行的阶段为0
+type VerifyTimings struct {
+	Lookup    time.Duration `json:"lookup"`    // 查询应用，校验状态和IP白名单
+	Signature time.Duration `json:"signature"` // 计算和比对签名
+	Replay    time.Duration `json:"replay"`    // 登记nonce
+	Total     time.Duration `json:"total"`
+}
+
+// VerifyResult 验签结果，验签失败时与 VerifyError 中的结果相同，只有已完成阶段的字段有值：
+//   - Reason 和 Timings.Total 总是有值，未执行阶段的耗时为0
+//   - AppKey 在查询应用、校验IP白名单和应用状态通过后有值，此前失败（如 ip_not_allowed、app_disabled）时为nil
+//   - CanonicalHash 在时间戳和算法校验通过后有值，missing_sign 和 sign_mismatch 时可用于排查
+//   - SignType 和 KeyID 只在签名匹配后有值，即验签通过或 replayed 时
+type VerifyResult struct {
+	AppKey   *AppKey  `json:"app_key,omitempty"`
+	SignType SignType `json:"sign_type,omitempty"` // 匹配的算法
+	KeyID    string   `json:"key_id,omitempty"`    // 匹配的密钥ID，非对称算法为空
+
+	Reason VerifyReason `json:"reason,omitempty"` // 失败原因，验签通过时为空
+	// CanonicalHash 参与签名的规范字符串（不含密钥）的SHA-256十六进制摘要，可与客户端比对排查签名不一致
+	CanonicalHash string        `json:"canonical_hash,omitempty"`
+	Timings       VerifyTimings `json:"timings"`
+}
+
+// VerifyError 验签失败的错误，errors.Is 可判断原始错误（如 ErrInvalidSign），errors.As 可获取验签结果
+type VerifyError struct {
+	Result *VerifyResult
+	Err    error
+}
+
+func (e *VerifyError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *VerifyError) Unwrap() error {
+	return e.Err
+}
+
+// Reason 返回失败原因
+func (e *VerifyError) Reason() VerifyReason {
+	return e.Result.Reason
+}
+
+// verifyReason 返回错误对应的失败原因
+func verifyReason(err error) VerifyReason {
+	switch {
+	case errors.Is(err, ErrMissingSign):
+		return ReasonMissingSign
+	case errors.Is(err, ErrInvalidSign):
+		return ReasonSignMismatch
+	case errors.Is(err, ErrInvalidRequest):
+		return ReasonInvalidRequest
+	case errors.Is(err, ErrAppNotFound), errors.Is(err, ErrInvalidCredential):
+		return ReasonAppNotFound
+	case errors.Is(err, ErrIPNotAllowed):
+		return ReasonIPNotAllowed
+	case errors.Is(err, ErrAppDisabled):
+		return ReasonAppDisabled
+	case errors.Is(err, ErrSignTypeNotAllowed), errors.Is(err, ErrUnsupportedSignType):
+		return ReasonSignTypeNotAllowed
+	case errors.Is(err, ErrMissingTimestamp):
+		return ReasonMissingTimestamp
+	case errors.Is(err, ErrExpiredRequest):
+		return ReasonExpired
+	case errors.Is(err, ErrMissingNonce):
+		return ReasonMissingNonce
+	case errors.Is(err, ErrReplayedRequest):
+		return ReasonReplayed
+	default:
+		return ReasonInternal
+	}
+}
+
+// canonicalHash 返回规范字符串的SHA-256十六进制摘要
+func canonicalHash(data map[string]interface{}) string {
+	sum := sha256.Sum256([]byte(buildCanonicalString(data)))
+	return hex.EncodeToString(sum[:])
+}
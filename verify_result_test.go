@@ -0,0 +1,159 @@
+package go_signature_sdk
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestVerifyReasons 测试各种验签失败的原因和错误类型
+func TestVerifyReasons(t *testing.T) {
+	sdk := NewSignatureSDK(&Config{KeyStore: NewMemoryKeyStore(), NonceStore: NewMemoryNonceStore()})
+	ctx := context.Background()
+	if err := sdk.CreateAppKey("test_app", "test_secret", []string{"127.0.0.1"}, nil); err != nil {
+		t.Fatalf("创建测试应用失败: %v", err)
+	}
+	if err := sdk.CreateAppKey("disabled_app", "test_secret", nil, nil); err != nil {
+		t.Fatalf("创建测试应用失败: %v", err)
+	}
+	if err := sdk.SetStatus(ctx, "disabled_app", StatusChange{Status: StatusSuspended}); err != nil {
+		t.Fatalf("暂停应用失败: %v", err)
+	}
+
+	data := map[string]interface{}{"user_id": "12345"}
+	signed := func(nonce string, timestamp int64) map[string]interface{} {
+		d := maps.Clone(data)
+		d["nonce"], d["timestamp"] = nonce, timestamp
+		d["sign"], _ = Sign(d, "test_secret")
+		return d
+	}
+	now := time.Now().Unix()
+
+	tests := []struct {
+		name   string
+		params *VerifyParams
+		reason VerifyReason
+		target error
+	}{
+		{"app_not_found", &VerifyParams{AppID: "missing", Data: signed("n1", now)}, ReasonAppNotFound, ErrAppNotFound},
+		{"ip", &VerifyParams{AppID: "test_app", ClientIP: "10.0.0.1", Data: signed("n2", now)}, ReasonIPNotAllowed, ErrIPNotAllowed},
+		{"invalid_ip", &VerifyParams{AppID: "test_app", ClientIP: "not-an-ip", Data: signed("n7", now)}, ReasonIPNotAllowed, ErrIPNotAllowed},
+		{"disabled", &VerifyParams{AppID: "disabled_app", Data: signed("n3", now)}, ReasonAppDisabled, ErrAppDisabled},
+		{"expired", &VerifyParams{AppID: "test_app", ClientIP: "127.0.0.1", Data: signed("n4", now-3600)}, ReasonExpired, ErrExpiredRequest},
+		{"invalid_timestamp", &VerifyParams{AppID: "test_app", ClientIP: "127.0.0.1", Data: map[string]interface{}{"timestamp": "yesterday", "sign": "x"}}, ReasonInvalidRequest, ErrInvalidRequest},
+		{"missing_sign", &VerifyParams{AppID: "test_app", ClientIP: "127.0.0.1", Data: maps.Clone(data)}, ReasonMissingSign, ErrInvalidSign},
+		{"mismatch", &VerifyParams{AppID: "test_app", ClientIP: "127.0.0.1", Sign: "wrong", Data: signed("n5", now)}, ReasonSignMismatch, ErrInvalidSign},
+		{"long_nonce", &VerifyParams{AppID: "test_app", ClientIP: "127.0.0.1", Data: signed(strings.Repeat("n", MaxNonceLength+1), now)}, ReasonInvalidRequest, ErrInvalidRequest},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := sdk.Verify(ctx, tc.params)
+			if !errors.Is(err, tc.target) {
+				t.Fatalf("期望错误 %v, 实际: %v", tc.target, err)
+			}
+			var verifyErr *VerifyError
+			if !errors.As(err, &verifyErr) {
+				t.Fatalf("期望 *VerifyError, 实际: %T", err)
+			}
+			if verifyErr.Result != result || verifyErr.Reason() != tc.reason {
+				t.Errorf("失败原因错误: 期望 %s, 实际 %s", tc.reason, result.Reason)
+			}
+		})
+	}
+
+	// 重放的请求：第一次通过，第二次失败
+	params := &VerifyParams{AppID: "test_app", ClientIP: "127.0.0.1", Data: signed("n6", now)}
+	result, err := sdk.Verify(ctx, params)
+	if err != nil {
+		t.Fatalf("签名验证失败: %v", err)
+	}
+	if result.Reason != "" || result.KeyID == "" || result.Timings.Total <= 0 {
+		t.Errorf("验签结果错误: %+v", result)
+	}
+	if result.CanonicalHash != canonicalHash(withoutField(params.Data, "sign")) {
+		t.Errorf("规范字符串摘要错误: %s", result.CanonicalHash)
+	}
+	result, err = sdk.Verify(ctx, params)
+	if !errors.Is(err, ErrReplayedRequest) || result.Reason != ReasonReplayed {
+		t.Errorf("期望重复请求错误, 实际: %v %s", err, result.Reason)
+	}
+
+	// 签名不一致时结果包含已匹配的应用和摘要，便于排查
+	result, _ = sdk.Verify(ctx, tests[7].params)
+	if result.AppKey == nil || result.AppKey.AppID != "test_app" || result.CanonicalHash == "" {
+		t.Errorf("签名不一致时结果不完整: %+v", result)
+	}
+
+	// 旧接口仍返回原始错误
+	if err := sdk.VerifySign(tests[1].params); err != ErrIPNotAllowed {
+		t.Errorf("期望IP不在白名单错误, 实际: %v", err)
+	}
+}
+
+// TestVerifyLog 测试 Verify 默认不输出日志，已废弃的接口仍输出脱敏的签名字符串
+func TestVerifyLog(t *testing.T) {
+	var buf bytes.Buffer
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(&buf)
+
+	sdk := createMemorySDK(t)
+	if err := sdk.CreateAppKey("test_app", "test_secret", nil, nil); err != nil {
+		t.Fatalf("创建测试应用失败: %v", err)
+	}
+	params := &VerifyParams{AppID: "test_app", Data: map[string]interface{}{"user_id": "1", "sign": "wrong"}}
+	if _, err := sdk.Verify(context.Background(), params); !errors.Is(err, ErrInvalidSign) {
+		t.Errorf("期望签名验证失败, 实际: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Verify 不应输出日志: %s", buf.String())
+	}
+
+	if err := sdk.VerifySign(params); err != ErrInvalidSign {
+		t.Errorf("期望签名验证失败, 实际: %v", err)
+	}
+	if !strings.Contains(buf.String(), "签名验证失败") || strings.Contains(buf.String(), "test_secret") {
+		t.Errorf("VerifySign 日志错误: %s", buf.String())
+	}
+
+	buf.Reset()
+	if err := VerifySignWith(params, SignTypeMD5, "test_secret"); err != ErrInvalidSign {
+		t.Errorf("期望签名验证失败, 实际: %v", err)
+	}
+	if !strings.Contains(buf.String(), "签名验证失败") || strings.Contains(buf.String(), "test_secret") {
+		t.Errorf("VerifySignWith 日志错误: %s", buf.String())
+	}
+}
+
+// TestMiddlewareVerifyError 测试中间件的错误处理函数可以获取验签结果
+func TestMiddlewareVerifyError(t *testing.T) {
+	sdk := createMemorySDK(t)
+	if err := sdk.CreateAppKey("test_app", "test_secret", nil, nil); err != nil {
+		t.Fatalf("创建测试应用失败: %v", err)
+	}
+
+	var reason VerifyReason
+	handler := HTTPMiddleware(sdk, WithErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+		var verifyErr *VerifyError
+		if errors.As(err, &verifyErr) {
+			reason = verifyErr.Reason()
+		}
+		DefaultErrorHandler(w, r, err)
+	}))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest(http.MethodGet, "/?user_id=1", nil)
+	req.Header.Set("X-App-ID", "test_app")
+	req.Header.Set("X-Sign", "wrong")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized || reason != ReasonSignMismatch {
+		t.Errorf("期望401和签名不一致, 实际: %d %s", w.Code, reason)
+	}
+}